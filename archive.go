@@ -0,0 +1,143 @@
+package gocloc
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// ArchiveSeparator separates the archive path and the path of an entry inside it
+// in the file names of the result, e.g. "vendor.tgz!src/main.go".
+const ArchiveSeparator = "!"
+
+type archiveFormat int
+
+const (
+	archiveNone archiveFormat = iota
+	archiveTar
+	archiveTarGz
+	archiveZip
+)
+
+// getArchiveFormat detects the archive format by the file name.
+func getArchiveFormat(name string) archiveFormat {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".tar"):
+		return archiveTar
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return archiveTarGz
+	case strings.HasSuffix(lower, ".zip"):
+		return archiveZip
+	}
+	return archiveNone
+}
+
+// IsArchive reports whether the path is an archive whose entries can be analyzed.
+func IsArchive(path string) bool {
+	return getArchiveFormat(path) != archiveNone
+}
+
+// walkArchive reads the regular file entries of the archive in their stored order
+// and calls fn for every entry which is not excluded by its name.
+// Ignore files inside the archive are not taken into account.
+func walkArchive(fsys fileSystem, archive string, opts *ClocOptions, fn func(name string, content []byte)) error {
+	fp, err := fsys.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+
+	visit := func(name string, info os.FileInfo, r io.Reader) error {
+		name = strings.TrimPrefix(path.Clean("/"+name), "/")
+		display := archive + ArchiveSeparator + name
+		if checkDefaultIgnore(name, info, false) || !checkOptionMatch(display, info, opts) {
+			return nil
+		}
+		content, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		fn(name, content)
+		return nil
+	}
+
+	switch getArchiveFormat(archive) {
+	case archiveTarGz:
+		gz, err := gzip.NewReader(fp)
+		if err != nil {
+			return fmt.Errorf("%s: %v", archive, err)
+		}
+		defer gz.Close()
+		return walkTar(archive, gz, visit)
+	case archiveTar:
+		return walkTar(archive, fp, visit)
+	case archiveZip:
+		return walkZip(archive, fp, visit)
+	}
+	return fmt.Errorf("%s: unknown archive format", archive)
+}
+
+func walkTar(archive string, r io.Reader, visit func(string, os.FileInfo, io.Reader) error) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", archive, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := visit(header.Name, header.FileInfo(), tr); err != nil {
+			return fmt.Errorf("%s: %v", archive, err)
+		}
+	}
+}
+
+func walkZip(archive string, r io.Reader, visit func(string, os.FileInfo, io.Reader) error) error {
+	// zip needs random access, files of the OS are not loaded in memory
+	var readerAt io.ReaderAt
+	var size int64
+	if f, ok := r.(*os.File); ok {
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		readerAt, size = f, info.Size()
+	} else {
+		content, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		readerAt, size = bytes.NewReader(content), int64(len(content))
+	}
+
+	zr, err := zip.NewReader(readerAt, size)
+	if err != nil {
+		return fmt.Errorf("%s: %v", archive, err)
+	}
+	for _, f := range zr.File {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("%s: %v", archive, err)
+		}
+		err = visit(f.Name, f.FileInfo(), rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %v", archive, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package gocloc
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+var testArchiveEntries = []struct {
+	name    string
+	content string
+}{
+	{"src/main.go", "package main\n\n// comment\nfunc main() {}\n"},
+	{"src/tool", "#!/bin/sh\necho 1\n"},
+	{"src/util.go", "package main\n\n// comment\nfunc main() {}\n"},
+	{".git/config", "[core]\n"},
+	{"README", "readme\n"},
+}
+
+func writeTestTar(t *testing.T, w io.Writer) {
+	t.Helper()
+	tw := tar.NewWriter(w)
+	if err := tw.WriteHeader(&tar.Header{Name: "src/", Typeflag: tar.TypeDir, Mode: 0o755}); err != nil {
+		t.Fatalf("WriteHeader() error. err=[%v]", err)
+	}
+	for _, entry := range testArchiveEntries {
+		header := &tar.Header{Name: entry.name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(entry.content))}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatalf("WriteHeader() error. err=[%v]", err)
+		}
+		if _, err := tw.Write([]byte(entry.content)); err != nil {
+			t.Fatalf("Write() error. err=[%v]", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("Close() error. err=[%v]", err)
+	}
+}
+
+func createTestArchive(t *testing.T, name string) string {
+	t.Helper()
+	archive := filepath.Join(t.TempDir(), name)
+	fp, err := os.Create(archive)
+	if err != nil {
+		t.Fatalf("os.Create() error. err=[%v]", err)
+	}
+	defer fp.Close()
+
+	switch getArchiveFormat(name) {
+	case archiveTar:
+		writeTestTar(t, fp)
+	case archiveTarGz:
+		gz := gzip.NewWriter(fp)
+		writeTestTar(t, gz)
+		if err := gz.Close(); err != nil {
+			t.Fatalf("Close() error. err=[%v]", err)
+		}
+	case archiveZip:
+		zw := zip.NewWriter(fp)
+		for _, entry := range testArchiveEntries {
+			w, err := zw.Create(entry.name)
+			if err != nil {
+				t.Fatalf("Create() error. err=[%v]", err)
+			}
+			if _, err := w.Write([]byte(entry.content)); err != nil {
+				t.Fatalf("Write() error. err=[%v]", err)
+			}
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatalf("Close() error. err=[%v]", err)
+		}
+	}
+	return archive
+}
+
+func TestGetArchiveFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		format archiveFormat
+	}{
+		{"vendor.tar", archiveTar},
+		{"vendor.tar.gz", archiveTarGz},
+		{"vendor.TGZ", archiveTarGz},
+		{"vendor.zip", archiveZip},
+		{"vendor.gz", archiveNone},
+		{"main.go", archiveNone},
+	}
+	for _, tt := range tests {
+		if format := getArchiveFormat(tt.name); format != tt.format {
+			t.Errorf("invalid logic. name=%v format=%v", tt.name, format)
+		}
+	}
+}
+
+func TestAnalyzeArchive(t *testing.T) {
+	for _, name := range []string{"vendor.tar", "vendor.tgz", "vendor.zip"} {
+		archive := createTestArchive(t, name)
+		result, err := NewProcessor(NewDefinedLanguages(), NewClocOptions()).Analyze([]string{archive})
+		if err != nil {
+			t.Fatalf("Analyze() error. archive=%v err=[%v]", name, err)
+		}
+
+		var files []string
+		for file := range result.Files {
+			files = append(files, file)
+		}
+		sort.Strings(files)
+		// util.go is a duplicate of main.go
+		expected := []string{archive + "!src/main.go", archive + "!src/tool"}
+		if !equalStrings(files, expected) {
+			t.Errorf("invalid logic. archive=%v files=%v", name, files)
+		}
+		if lang := result.Languages["Bourne Shell"]; lang == nil || lang.Code != 2 {
+			t.Errorf("invalid logic. archive=%v shell=%+v", name, lang)
+		}
+		if lang := result.Languages["Go"]; lang == nil || lang.Code != 2 || lang.Comments != 1 || lang.Blanks != 1 {
+			t.Errorf("invalid logic. archive=%v go=%+v", name, lang)
+		}
+	}
+}
+
+func TestAnalyzeArchiveBroken(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "broken.tgz")
+	if err := os.WriteFile(archive, []byte("not a gzip file"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() error. err=[%v]", err)
+	}
+	if _, err := NewProcessor(NewDefinedLanguages(), NewClocOptions()).Analyze([]string{archive}); err == nil {
+		t.Errorf("invalid logic. broken archive should be an error")
+	}
+}
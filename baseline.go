@@ -0,0 +1,216 @@
+package gocloc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// BaselineDelta is the difference of the counts of a language, a file or the total
+// between a baseline and the current result. The counts are current minus baseline.
+type BaselineDelta struct {
+	Name string `json:"name"`
+	// Lang is the language of a file.
+	Lang string `json:"language,omitempty"`
+	// Status is DiffAdded or DiffRemoved if the language or the file is only in
+	// the current result or the baseline, DiffModified if its counts changed, else DiffSame.
+	Status   DiffStatus `json:"status"`
+	Files    int32      `json:"files"`
+	Code     int32      `json:"code"`
+	Comments int32      `json:"comment"`
+	Blanks   int32      `json:"blank"`
+}
+
+// BaselineResult is the comparison of a result with a baseline, such as a saved report.
+type BaselineResult struct {
+	// Languages are the deltas of the languages of both results, sorted by name.
+	Languages []BaselineDelta `json:"languages"`
+	// Files are the deltas of the added, removed and modified files, sorted by name.
+	// The files are compared only if both results have the files.
+	Files []BaselineDelta `json:"files,omitempty"`
+	Total BaselineDelta   `json:"total"`
+}
+
+// CompareResults compares the current result with the baseline result.
+// The files are paired by name, so both results should count the same paths.
+func CompareResults(baseline, current *Result) *BaselineResult {
+	b := &BaselineResult{
+		Total: newBaselineDelta("TOTAL",
+			ClocLanguage{FilesCount: baseline.Total.Total, Code: baseline.Total.Code,
+				Comments: baseline.Total.Comments, Blanks: baseline.Total.Blanks},
+			ClocLanguage{FilesCount: current.Total.Total, Code: current.Total.Code,
+				Comments: current.Total.Comments, Blanks: current.Total.Blanks}),
+	}
+
+	languages := make(map[string]struct{})
+	for name, language := range baseline.Languages {
+		if language.FilesCount() != 0 {
+			languages[name] = struct{}{}
+		}
+	}
+	for name, language := range current.Languages {
+		if language.FilesCount() != 0 {
+			languages[name] = struct{}{}
+		}
+	}
+	for name := range languages {
+		delta := newBaselineDelta(name, languageCounts(baseline.Languages[name]), languageCounts(current.Languages[name]))
+		if baseline.Languages[name] == nil || baseline.Languages[name].FilesCount() == 0 {
+			delta.Status = DiffAdded
+		} else if current.Languages[name] == nil || current.Languages[name].FilesCount() == 0 {
+			delta.Status = DiffRemoved
+		}
+		b.Languages = append(b.Languages, delta)
+	}
+	sort.Slice(b.Languages, func(i, j int) bool {
+		return b.Languages[i].Name < b.Languages[j].Name
+	})
+
+	if len(baseline.Files) == 0 || len(current.Files) == 0 {
+		return b
+	}
+	for name, file := range current.Files {
+		var delta BaselineDelta
+		if old, ok := baseline.Files[name]; ok {
+			delta = newBaselineDelta(name, fileCounts(old), fileCounts(file))
+		} else {
+			delta = newBaselineDelta(name, ClocLanguage{}, fileCounts(file))
+			delta.Status = DiffAdded
+		}
+		delta.Lang = file.Lang
+		if delta.Status != DiffSame {
+			b.Files = append(b.Files, delta)
+		}
+	}
+	for name, file := range baseline.Files {
+		if _, ok := current.Files[name]; !ok {
+			delta := newBaselineDelta(name, fileCounts(file), ClocLanguage{})
+			delta.Lang = file.Lang
+			delta.Status = DiffRemoved
+			b.Files = append(b.Files, delta)
+		}
+	}
+	sort.Slice(b.Files, func(i, j int) bool {
+		return b.Files[i].Name < b.Files[j].Name
+	})
+	return b
+}
+
+func newBaselineDelta(name string, baseline, current ClocLanguage) BaselineDelta {
+	delta := BaselineDelta{
+		Name:     name,
+		Status:   DiffSame,
+		Files:    current.FilesCount - baseline.FilesCount,
+		Code:     current.Code - baseline.Code,
+		Comments: current.Comments - baseline.Comments,
+		Blanks:   current.Blanks - baseline.Blanks,
+	}
+	if delta.Files != 0 || delta.Code != 0 || delta.Comments != 0 || delta.Blanks != 0 {
+		delta.Status = DiffModified
+	}
+	return delta
+}
+
+func languageCounts(language *Language) ClocLanguage {
+	if language == nil {
+		return ClocLanguage{}
+	}
+	return ClocLanguage{FilesCount: language.FilesCount(), Code: language.Code,
+		Comments: language.Comments, Blanks: language.Blanks}
+}
+
+func fileCounts(file *ClocFile) ClocLanguage {
+	return ClocLanguage{FilesCount: 1, Code: file.Code, Comments: file.Comments, Blanks: file.Blanks}
+}
+
+// signed formats a delta with its sign, except 0.
+func signed(n int32) string {
+	if n == 0 {
+		return "0"
+	}
+	return fmt.Sprintf("%+d", n)
+}
+
+// RenderBaseline writes the comparison with a baseline to w in the default, markdown or json output type.
+// With opts.ByFile, the rows are the changed files, whose files column is +1 if added and -1 if removed.
+func RenderBaseline(w io.Writer, result *BaselineResult, format string, opts *RenderOptions) error {
+	if opts == nil {
+		opts = NewRenderOptions()
+	}
+	switch format {
+	case OutputTypeJSON:
+		return writeJSON(w, result)
+	case OutputTypeDefault, OutputTypeMarkdown:
+	default:
+		return fmt.Errorf("unknown output type of baseline: %s", format)
+	}
+
+	rows := result.Languages
+	header := languageHeader
+	nameLen := languageNameLen
+	if opts.ByFile {
+		rows = result.Files
+		header = fileHeader
+		for _, row := range rows {
+			nameLen = max(nameLen, len(row.Name))
+		}
+	}
+
+	t := &tableWriter{w: w}
+	if format == OutputTypeMarkdown {
+		t.printf("| %-[1]*[2]s | files | blank | comment | code |\n", nameLen, header)
+		t.printf("|:%s|------:|------:|--------:|-----:|\n", strings.Repeat("-", nameLen+1))
+		for _, row := range rows {
+			t.printf("| %-[1]*[2]s | %5[3]s | %5[4]s | %7[5]s | %4[6]s |\n",
+				nameLen, row.Name, signed(row.Files), signed(row.Blanks), signed(row.Comments), signed(row.Code))
+		}
+		t.printf("| %-[1]*[2]s | %5[3]s | %5[4]s | %7[5]s | %4[6]s |\n", nameLen, "**TOTAL**",
+			signed(result.Total.Files), signed(result.Total.Blanks), signed(result.Total.Comments), signed(result.Total.Code))
+		return t.err
+	}
+
+	rowLen := nameLen + len(commonHeader) + 2
+	t.printf("%.[2]*[1]s\n", defaultOutputSeparator, rowLen)
+	t.printf("%-[2]*[1]s %[3]s\n", header, nameLen+1, commonHeader)
+	t.printf("%.[2]*[1]s\n", defaultOutputSeparator, rowLen)
+	for _, row := range rows {
+		t.printf("%-[1]*[2]s %6[3]s %14[4]s %14[5]s %14[6]s\n",
+			nameLen, row.Name, signed(row.Files), signed(row.Blanks), signed(row.Comments), signed(row.Code))
+	}
+	t.printf("%.[2]*[1]s\n", defaultOutputSeparator, rowLen)
+	t.printf("%-[1]*[2]s %6[3]s %14[4]s %14[5]s %14[6]s\n", nameLen, "TOTAL",
+		signed(result.Total.Files), signed(result.Total.Blanks), signed(result.Total.Comments), signed(result.Total.Code))
+	t.printf("%.[2]*[1]s\n", defaultOutputSeparator, rowLen)
+	return t.err
+}
+
+// RenderWithBaseline writes the result followed by its comparison with a baseline to w
+// in the default, markdown or json output type. The json output type is an object
+// of the json output of the result as "result" and of the comparison as "baseline".
+func RenderWithBaseline(w io.Writer, result *Result, baseline *BaselineResult, format string, opts *RenderOptions) error {
+	switch format {
+	case OutputTypeJSON:
+		var buf bytes.Buffer
+		if err := Render(&buf, result, format, opts); err != nil {
+			return err
+		}
+		return writeJSON(w, struct {
+			Result   json.RawMessage `json:"result"`
+			Baseline *BaselineResult `json:"baseline"`
+		}{buf.Bytes(), baseline})
+	case OutputTypeDefault, OutputTypeMarkdown:
+	default:
+		return fmt.Errorf("unknown output type of baseline: %s", format)
+	}
+
+	if err := Render(w, result, format, opts); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(w); err != nil {
+		return err
+	}
+	return RenderBaseline(w, baseline, format, opts)
+}
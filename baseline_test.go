@@ -0,0 +1,151 @@
+package gocloc
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func newTestBaselineResults() (*Result, *Result) {
+	baseline := newTestRenderResult()
+	current := newTestRenderResult()
+	current.Files["main.go"].Code = 25
+	current.Languages["Go"].Code = 25
+	delete(current.Files, "util.py")
+	delete(current.Languages, "Python")
+	current.Files["lib.rs"] = &ClocFile{Name: "lib.rs", Lang: "Rust", Code: 7, Comments: 1}
+	current.Languages["Rust"] = &Language{Name: "Rust", Files: []string{"lib.rs"}, Code: 7, Comments: 1}
+	current.Total = &Language{Total: 2, Code: 32, Comments: 6, Blanks: 3}
+	return baseline, current
+}
+
+func TestCompareResults(t *testing.T) {
+	b := CompareResults(newTestBaselineResults())
+
+	expected := []BaselineDelta{
+		{Name: "Go", Status: DiffModified, Code: 5},
+		{Name: "Python", Status: DiffRemoved, Files: -1, Code: -10, Comments: -1, Blanks: -1},
+		{Name: "Rust", Status: DiffAdded, Files: 1, Code: 7, Comments: 1},
+	}
+	if len(b.Languages) != len(expected) {
+		t.Fatalf("invalid logic. languages=%+v", b.Languages)
+	}
+	for i, delta := range expected {
+		if b.Languages[i] != delta {
+			t.Errorf("invalid logic. language=%+v expected=%+v", b.Languages[i], delta)
+		}
+	}
+
+	expected = []BaselineDelta{
+		{Name: "lib.rs", Lang: "Rust", Status: DiffAdded, Files: 1, Code: 7, Comments: 1},
+		{Name: "main.go", Lang: "Go", Status: DiffModified, Code: 5},
+		{Name: "util.py", Lang: "Python", Status: DiffRemoved, Files: -1, Code: -10, Comments: -1, Blanks: -1},
+	}
+	if len(b.Files) != len(expected) {
+		t.Fatalf("invalid logic. files=%+v", b.Files)
+	}
+	for i, delta := range expected {
+		if b.Files[i] != delta {
+			t.Errorf("invalid logic. file=%+v expected=%+v", b.Files[i], delta)
+		}
+	}
+
+	if b.Total != (BaselineDelta{Name: "TOTAL", Status: DiffModified, Code: 2, Blanks: -1}) {
+		t.Errorf("invalid logic. total=%+v", b.Total)
+	}
+
+	same := CompareResults(newTestRenderResult(), newTestRenderResult())
+	if len(same.Files) != 0 || same.Total.Status != DiffSame || same.Languages[0].Status != DiffSame {
+		t.Errorf("invalid logic. same=%+v", same)
+	}
+}
+
+func TestRenderBaseline(t *testing.T) {
+	b := CompareResults(newTestBaselineResults())
+
+	var buf bytes.Buffer
+	if err := RenderBaseline(&buf, b, OutputTypeDefault, nil); err != nil {
+		t.Fatalf("RenderBaseline() error. err=[%v]", err)
+	}
+	expected := `-------------------------------------------------------------------------------
+Language                     files          blank        comment           code
+-------------------------------------------------------------------------------
+Go                               0              0              0             +5
+Python                          -1             -1             -1            -10
+Rust                            +1              0             +1             +7
+-------------------------------------------------------------------------------
+TOTAL                            0             -1              0             +2
+-------------------------------------------------------------------------------
+`
+	if buf.String() != expected {
+		t.Errorf("invalid result. '%s'", buf.String())
+	}
+
+	opts := NewRenderOptions()
+	opts.ByFile = true
+	buf.Reset()
+	if err := RenderBaseline(&buf, b, OutputTypeMarkdown, opts); err != nil {
+		t.Fatalf("RenderBaseline() error. err=[%v]", err)
+	}
+	expected = `| File                        | files | blank | comment | code |
+|:----------------------------|------:|------:|--------:|-----:|
+| lib.rs                      |    +1 |     0 |      +1 |   +7 |
+| main.go                     |     0 |     0 |       0 |   +5 |
+| util.py                     |    -1 |    -1 |      -1 |  -10 |
+| **TOTAL**                   |     0 |    -1 |       0 |   +2 |
+`
+	if buf.String() != expected {
+		t.Errorf("invalid result. '%s'", buf.String())
+	}
+
+	buf.Reset()
+	if err := RenderBaseline(&buf, b, OutputTypeJSON, nil); err != nil {
+		t.Fatalf("RenderBaseline() error. err=[%v]", err)
+	}
+	var decoded BaselineResult
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error. err=[%v]", err)
+	}
+	if len(decoded.Files) != 3 || decoded.Files[0].Status != DiffAdded || decoded.Total.Code != 2 {
+		t.Errorf("invalid logic. decoded=%+v", decoded)
+	}
+
+	if err := RenderBaseline(&buf, b, OutputTypeYAML, nil); err == nil {
+		t.Errorf("invalid logic. yaml output type should be an error")
+	}
+}
+
+func TestRenderWithBaseline(t *testing.T) {
+	baseline, current := newTestBaselineResults()
+	b := CompareResults(baseline, current)
+
+	var buf bytes.Buffer
+	if err := RenderWithBaseline(&buf, current, b, OutputTypeDefault, nil); err != nil {
+		t.Fatalf("RenderWithBaseline() error. err=[%v]", err)
+	}
+	if !strings.Contains(buf.String(), "\nTOTAL                            2              3              6             32\n") ||
+		!strings.Contains(buf.String(), "-\n\n---") ||
+		!strings.Contains(buf.String(), "\nTOTAL                            0             -1              0             +2\n") {
+		t.Errorf("invalid result. '%s'", buf.String())
+	}
+
+	buf.Reset()
+	if err := RenderWithBaseline(&buf, current, b, OutputTypeJSON, nil); err != nil {
+		t.Fatalf("RenderWithBaseline() error. err=[%v]", err)
+	}
+	var decoded struct {
+		Result   JSONLanguagesResult `json:"result"`
+		Baseline BaselineResult      `json:"baseline"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error. err=[%v]", err)
+	}
+	if decoded.Result.Total.Code != 32 || decoded.Baseline.Total.Code != 2 {
+		t.Errorf("invalid logic. decoded=%+v", decoded)
+	}
+
+	if err := RenderWithBaseline(&buf, current, b, OutputTypeCSV, nil); err == nil {
+		t.Errorf("invalid logic. csv output type should be an error")
+	}
+}
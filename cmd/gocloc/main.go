@@ -1,11 +1,14 @@
 package main
 
 import (
-	"encoding/json"
+	"bytes"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/hhatto/gocloc"
 	"github.com/jessevdk/go-flags"
@@ -17,261 +20,267 @@ var Version string
 // GitCommit is git commit hash string for gocloc command
 var GitCommit string
 
-// OutputTypeDefault is cloc's text output format for --output-type option
-const OutputTypeDefault string = "default"
-
-// OutputTypeClocXML is Cloc's XML output format for --output-type option
-const OutputTypeClocXML string = "cloc-xml"
-
-// OutputTypeSloccount is Sloccount output format for --output-type option
-const OutputTypeSloccount string = "sloccount"
-
-// OutputTypeJSON is JSON output format for --output-type option
-const OutputTypeJSON string = "json"
-
-// OutputTypeMarkdown is Markdown output format for --output-type option
-const OutputTypeMarkdown string = "markdown"
-
-const (
-	fileHeader             string = "File"
-	languageHeader         string = "Language"
-	commonHeader           string = "files          blank        comment           code"
-	defaultOutputSeparator string = "-------------------------------------------------------------------------" +
-		"-------------------------------------------------------------------------" +
-		"-------------------------------------------------------------------------"
-)
-
-var rowLen = 79
-
 // CmdOptions is gocloc command options.
 // It is necessary to use notation that follows go-flags.
 type CmdOptions struct {
-	ByFile         bool   `long:"by-file" description:"report results for every encountered source file"`
-	SortTag        string `long:"sort" default:"code" description:"sort based on a certain column" choice:"name" choice:"files" choice:"blank" choice:"comment" choice:"code"`
-	OutputType     string `long:"output-type" default:"default" description:"output type [values: default,markdown,cloc-xml,sloccount,json]"`
-	ExcludeExt     string `long:"exclude-ext" description:"exclude file name extensions (separated commas)"`
-	IncludeLang    string `long:"include-lang" description:"include language name (separated commas)"`
-	Match          string `long:"match" description:"include file name (regex)"`
-	NotMatch       string `long:"not-match" description:"exclude file name (regex)"`
-	MatchDir       string `long:"match-d" description:"include dir name (regex)"`
-	NotMatchDir    string `long:"not-match-d" description:"exclude dir name (regex)"`
-	Fullpath       bool   `long:"fullpath" description:"apply match/not-match options to full file paths instead of base names"`
-	Debug          bool   `long:"debug" description:"dump debug log for developer"`
-	SkipDuplicated bool   `long:"skip-duplicated" description:"skip duplicated files"`
-	ShowLang       bool   `long:"show-lang" description:"print about all languages and extensions"`
-	ShowVersion    bool   `long:"version" description:"print version info"`
+	ByFile          bool     `long:"by-file" description:"report results for every encountered source file"`
+	ByFileByLang    bool     `long:"by-file-by-lang" description:"report results for every source file followed by the languages (default, markdown, json and cloc-xml output types)"`
+	ByDir           bool     `long:"by-dir" description:"report results for every directory"`
+	ByDirDepth      int      `long:"by-dir-depth" description:"number of path elements of the directories of --by-dir, deeper directories are counted in their parent (default: no limit)"`
+	SortTag         string   `long:"sort" default:"code" description:"sort based on a certain column" choice:"name" choice:"files" choice:"blank" choice:"comment" choice:"code"`
+	OutputType      string   `long:"output-type" default:"default" description:"output type [values: default,markdown,cloc-xml,sloccount,json,cloc-json,csv,tsv,yaml,sql,html]"`
+	Template        string   `long:"template" description:"render the result with a Go text/template file instead of the output type"`
+	Out             []string `long:"out" description:"write the output to the file instead of the standard output, in the output type of its extension (.json, .md, .xml, .csv, .tsv, .yaml, .sql, .html) or else --output-type (can be specified multiple times)"`
+	CSVDelimiter    string   `long:"csv-delimiter" default:"," description:"field delimiter of the csv output type"`
+	ExcludeExt      string   `long:"exclude-ext" description:"exclude file name extensions (separated commas)"`
+	IncludeLang     string   `long:"include-lang" description:"include language name (separated commas)"`
+	Match           string   `long:"match" description:"include file name (regex)"`
+	NotMatch        string   `long:"not-match" description:"exclude file name (regex)"`
+	MatchDir        string   `long:"match-d" description:"include dir name (regex)"`
+	NotMatchDir     string   `long:"not-match-d" description:"exclude dir name (regex)"`
+	Fullpath        bool     `long:"fullpath" description:"apply match/not-match options to full file paths instead of base names"`
+	Debug           bool     `long:"debug" description:"dump debug log for developer"`
+	SkipDuplicated  bool     `long:"skip-duplicated" description:"skip duplicated files"`
+	NoIgnore        bool     `long:"no-ignore" description:"don't respect .gitignore, .ignore and .gocloc-ignore files"`
+	IgnoreFile      []string `long:"ignore-file" description:"additional gitignore formatted file (can be specified multiple times)"`
+	Jobs            int      `long:"jobs" description:"number of files analyzed in parallel (default: number of CPUs)"`
+	DocstringAsCode bool     `long:"docstring-as-code" description:"count the docstrings of Python, Cython and Vyper as code"`
+	ReadLangDef     string   `long:"read-lang-def" description:"load language definitions from the file, merged with the built-in ones (cloc format, YAML or JSON)"`
+	ForceLangDef    string   `long:"force-lang-def" description:"load language definitions from the file, replacing the built-in ones (cloc format, YAML or JSON)"`
+	SQLProject      string   `long:"sql-project" description:"project name of the sql output type (default: the paths)"`
+	SQLAppend       bool     `long:"sql-append" description:"omit the CREATE TABLE statements of the sql output type"`
+	GitRef          string   `long:"git-ref" description:"count the files of a git revision (commit, tag or branch) instead of the working tree"`
+	ChangedSince    string   `long:"changed-since" description:"count only the files added or modified since the merge base of the git revision and HEAD, followed by their differences from the merge base (default, markdown and json output types)"`
+	Untracked       bool     `long:"untracked" description:"count the untracked files as added with --changed-since"`
+	MaxCode         int32    `long:"max-code" description:"fail when the total code lines exceed the limit"`
+	MinCommentRatio float64  `long:"min-comment-ratio" description:"fail when the percentage of comment lines in the comment and code lines is below the limit"`
+	MaxFileCode     int32    `long:"max-file-code" description:"fail when the code lines of a file exceed the limit"`
+	LangLimit       []string `long:"lang-limit" description:"limit of a language as LANG:RULE=VALUE, RULE is max-code, min-comment-ratio, max-file-code or max-code-growth (can be specified multiple times)"`
+	GateConfig      string   `long:"gate-config" description:"read the limits from the YAML or JSON file, overridden by the limit options"`
+	Baseline        string   `long:"baseline" description:"report the differences from a saved report (gocloc json, cloc-xml, yaml, ...) instead of the counts (default, markdown and json output types)"`
+	MaxCodeGrowth   int32    `long:"max-code-growth" description:"fail when the code lines added since the --baseline report exceed the budget"`
+	ShowLang        bool     `long:"show-lang" description:"print about all languages and extensions"`
+	ShowVersion     bool     `long:"version" description:"print version info"`
 }
 
-type outputBuilder struct {
-	opts   *CmdOptions
-	result *gocloc.Result
+// outputTypesOfExt are the output types of the --out file name extensions.
+var outputTypesOfExt = map[string]string{
+	".json":     gocloc.OutputTypeJSON,
+	".md":       gocloc.OutputTypeMarkdown,
+	".markdown": gocloc.OutputTypeMarkdown,
+	".xml":      gocloc.OutputTypeClocXML,
+	".csv":      gocloc.OutputTypeCSV,
+	".tsv":      gocloc.OutputTypeTSV,
+	".yaml":     gocloc.OutputTypeYAML,
+	".yml":      gocloc.OutputTypeYAML,
+	".sql":      gocloc.OutputTypeSQL,
+	".html":     gocloc.OutputTypeHTML,
+	".htm":      gocloc.OutputTypeHTML,
 }
 
-func newOutputBuilder(result *gocloc.Result, opts *CmdOptions) *outputBuilder {
-	return &outputBuilder{
-		opts,
-		result,
+// writeOutputs renders the result to the standard output, or to every --out file
+// in the output type of its extension.
+func writeOutputs(opts *CmdOptions, render func(w io.Writer, outputType string) error) error {
+	outputType := opts.OutputType
+	if opts.Template != "" {
+		outputType = gocloc.OutputTypeTemplate
+	}
+	if len(opts.Out) == 0 {
+		return render(os.Stdout, outputType)
 	}
-}
 
-func (o *outputBuilder) WriteHeader() {
-	maxPathLen := o.result.MaxPathLength
-	headerLen := 28
-	header := languageHeader
-
-	if o.opts.ByFile {
-		headerLen = maxPathLen + 1
-		rowLen = maxPathLen + len(commonHeader) + 2
-		header = fileHeader
-	}
-
-	if o.opts.OutputType == OutputTypeDefault {
-		fmt.Printf("%.[2]*[1]s\n", defaultOutputSeparator, rowLen)
-		fmt.Printf("%-[2]*[1]s %[3]s\n", header, headerLen, commonHeader)
-		fmt.Printf("%.[2]*[1]s\n", defaultOutputSeparator, rowLen)
-	}
-
-	if o.opts.OutputType == OutputTypeMarkdown {
-		allHeaders := fmt.Sprintf("%s%s%s", header, strings.Repeat(" ", headerLen), commonHeader)
-		headerString := "| " + gocloc.InsertPipesInTheMiddle(allHeaders)
-		fmt.Println(headerString)
-
-		for i := 0; i < len(headerString); i++ {
-			if headerString[i] == '|' {
-				fmt.Print("|")
-			} else {
-				if i == 1 {
-					// Align the first column to the left
-					fmt.Print(":")
-				} else {
-					// Align the other columns to the right
-					if headerString[i+1] == '|' && i > headerLen {
-						fmt.Print(":")
-					} else {
-						fmt.Print("-")
-					}
-				}
-			}
+	for _, out := range opts.Out {
+		t, ok := outputTypesOfExt[strings.ToLower(filepath.Ext(out))]
+		if !ok {
+			t = outputType
+		}
+		fp, err := os.Create(out)
+		if err != nil {
+			return err
+		}
+		err = render(fp, t)
+		if closeErr := fp.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", out, err)
 		}
-
-		fmt.Println()
 	}
+	return nil
 }
 
-func (o *outputBuilder) WriteFooter() {
-	total := o.result.Total
-	maxPathLen := o.result.MaxPathLength
-
-	if o.opts.OutputType == OutputTypeDefault {
-		fmt.Printf("%.[2]*[1]s\n", defaultOutputSeparator, rowLen)
-		if o.opts.ByFile {
-			fmt.Printf("%-[1]*[2]v %6[3]v %14[4]v %14[5]v %14[6]v\n",
-				maxPathLen, "TOTAL", total.Total, total.Blanks, total.Comments, total.Code)
-		} else {
-			fmt.Printf("%-27v %6v %14v %14v %14v\n",
-				"TOTAL", total.Total, total.Blanks, total.Comments, total.Code)
-		}
-		fmt.Printf("%.[2]*[1]s\n", defaultOutputSeparator, rowLen)
+// readReport reads the report in the output type of its extension,
+// or else of its first character.
+func readReport(path string) (*gocloc.Result, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
 	}
-
-	if o.opts.OutputType == OutputTypeMarkdown {
-		if o.opts.ByFile {
-			fmt.Printf("| %-[1]*[2]v |%10v|%12v|%14v|%8v |\n", maxPathLen, "", "", "", "", "")
-			fmt.Printf("| %-[1]*[2]v |%9v |%11v |%13v |%8v |\n", maxPathLen, "TOTAL", total.Total, total.Blanks, total.Comments, total.Code)
-		} else {
-			fmt.Printf("| %21v|%22v|%12v|%14v|%8v |\n", "", "", "", "", "")
-			fmt.Printf("| %20v |%21v |%11v |%13v |%8v |\n", "TOTAL", total.Total, total.Blanks, total.Comments, total.Code)
+	outputType, ok := outputTypesOfExt[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		switch trimmed := bytes.TrimSpace(content); {
+		case bytes.HasPrefix(trimmed, []byte("{")):
+			outputType = gocloc.OutputTypeJSON
+		case bytes.HasPrefix(trimmed, []byte("<")):
+			outputType = gocloc.OutputTypeClocXML
+		case bytes.HasPrefix(trimmed, []byte("files")), bytes.HasPrefix(trimmed, []byte("language")):
+			outputType = gocloc.OutputTypeCSV
+		case bytes.HasPrefix(trimmed, []byte("create table")), bytes.HasPrefix(trimmed, []byte("begin")),
+			bytes.HasPrefix(trimmed, []byte("insert into")):
+			outputType = gocloc.OutputTypeSQL
+		default:
+			outputType = gocloc.OutputTypeYAML
 		}
 	}
+	result, err := gocloc.ReadResult(bytes.NewReader(content), outputType)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return result, nil
 }
 
-func writeResultWithByFile(opts *CmdOptions, result *gocloc.Result) {
-	clocFiles := result.Files
-	total := result.Total
-	maxPathLen := result.MaxPathLength
-
-	var sortedFiles gocloc.ClocFiles
-	for _, file := range clocFiles {
-		sortedFiles = append(sortedFiles, *file)
-	}
-	switch opts.SortTag {
-	case "name":
-		sortedFiles.SortByName()
-	case "comment":
-		sortedFiles.SortByComments()
-	case "blank":
-		sortedFiles.SortByBlanks()
-	default:
-		sortedFiles.SortByCode()
-	}
-
-	switch opts.OutputType {
-	case OutputTypeClocXML:
-		t := gocloc.XMLTotalFiles{
-			Code:    total.Code,
-			Comment: total.Comments,
-			Blank:   total.Blanks,
+// loadGate returns the gate of the --gate-config file and the limit options,
+// or nil if no limit is set.
+func loadGate(opts *CmdOptions) (*gocloc.Gate, error) {
+	gate := &gocloc.Gate{}
+	if opts.GateConfig != "" {
+		fp, err := os.Open(opts.GateConfig)
+		if err != nil {
+			return nil, err
 		}
-		f := &gocloc.XMLResultFiles{
-			Files: sortedFiles,
-			Total: t,
+		defer fp.Close()
+		if gate, err = gocloc.ReadGate(fp); err != nil {
+			return nil, fmt.Errorf("%s: %v", opts.GateConfig, err)
 		}
-		xmlResult := gocloc.XMLResult{
-			XMLFiles: f,
+	}
+	if opts.MaxCode > 0 {
+		gate.MaxCode = opts.MaxCode
+	}
+	if opts.MinCommentRatio > 0 {
+		gate.MinCommentRatio = opts.MinCommentRatio
+	}
+	if opts.MaxFileCode > 0 {
+		gate.MaxFileCode = opts.MaxFileCode
+	}
+	if opts.MaxCodeGrowth > 0 {
+		gate.MaxCodeGrowth = opts.MaxCodeGrowth
+	}
+	for _, limit := range opts.LangLimit {
+		lang, rule, ok := strings.Cut(limit, ":")
+		rule, value, ok2 := strings.Cut(rule, "=")
+		if !ok || !ok2 || lang == "" {
+			return nil, fmt.Errorf("invalid --lang-limit %q, expected LANG:RULE=VALUE", limit)
 		}
-		xmlResult.Encode()
-	case OutputTypeSloccount:
-		for _, file := range sortedFiles {
-			p := ""
-			if strings.HasPrefix(file.Name, "./") || string(file.Name[0]) == "/" {
-				splitPaths := strings.Split(file.Name, string(os.PathSeparator))
-				if len(splitPaths) >= 3 {
-					p = splitPaths[1]
-				}
-			}
-			fmt.Printf("%v\t%v\t%v\t%v\n",
-				file.Code, file.Lang, p, file.Name)
+		if gate.Languages == nil {
+			gate.Languages = make(map[string]gocloc.GateLimits)
 		}
-	case OutputTypeJSON:
-		jsonResult := gocloc.NewJSONFilesResultFromCloc(total, sortedFiles)
-		buf, err := json.Marshal(jsonResult)
-		if err != nil {
-			fmt.Println(err)
-			panic("json marshal error")
+		limits := gate.Languages[lang]
+		var err error
+		switch rule {
+		case "max-code":
+			_, err = fmt.Sscan(value, &limits.MaxCode)
+		case "min-comment-ratio":
+			_, err = fmt.Sscan(value, &limits.MinCommentRatio)
+		case "max-file-code":
+			_, err = fmt.Sscan(value, &limits.MaxFileCode)
+		case "max-code-growth":
+			_, err = fmt.Sscan(value, &limits.MaxCodeGrowth)
+		default:
+			return nil, fmt.Errorf("invalid --lang-limit %q, unknown rule %s", limit, rule)
 		}
-		os.Stdout.Write(buf)
-	case OutputTypeMarkdown:
-		for _, file := range sortedFiles {
-			clocFile := file
-			fmt.Printf("| %-[1]*[2]s |%8[3]v  |%11[4]v |%13[5]v |%8[6]v |\n",
-				maxPathLen, file.Name, 1, clocFile.Blanks, clocFile.Comments, clocFile.Code)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --lang-limit %q: %v", limit, err)
 		}
+		gate.Languages[lang] = limits
+	}
 
-	default:
-		for _, file := range sortedFiles {
-			clocFile := file
-			fmt.Printf("%-[1]*[2]s %21[3]v %14[4]v %14[5]v\n",
-				maxPathLen, file.Name, clocFile.Blanks, clocFile.Comments, clocFile.Code)
-		}
+	if gate.GateLimits == (gocloc.GateLimits{}) && len(gate.Languages) == 0 {
+		return nil, nil
 	}
+	return gate, nil
 }
 
-func (o *outputBuilder) WriteResult() {
-	// write header
-	o.WriteHeader()
+// hasGrowthBudget reports whether the gate has a growth budget, checked against a baseline.
+func hasGrowthBudget(gate *gocloc.Gate) bool {
+	if gate.MaxCodeGrowth > 0 {
+		return true
+	}
+	for _, limits := range gate.Languages {
+		if limits.MaxCodeGrowth > 0 {
+			return true
+		}
+	}
+	return false
+}
 
-	clocLangs := o.result.Languages
-	total := o.result.Total
+// writeResult writes the result, or its differences from the --baseline report,
+// and checks the gate. The result of the changed files is followed by its differences
+// from changedBase, their result in the merge base of --changed-since.
+func writeResult(opts *CmdOptions, result, changedBase *gocloc.Result, renderOpts *gocloc.RenderOptions, gate *gocloc.Gate) {
+	var baseline *gocloc.BaselineResult
+	if changedBase != nil {
+		baseline = gocloc.CompareResults(changedBase, result)
+	} else if opts.Baseline != "" {
+		previous, err := readReport(opts.Baseline)
+		if err != nil {
+			fmt.Printf("fail to read baseline. error: %v\n", err)
+			os.Exit(1)
+		}
+		baseline = gocloc.CompareResults(previous, result)
+	}
 
-	if o.opts.ByFile {
-		writeResultWithByFile(o.opts, o.result)
-	} else {
-		var sortedLanguages gocloc.Languages
-		for _, language := range clocLangs {
-			if len(language.Files) != 0 {
-				sortedLanguages = append(sortedLanguages, *language)
-			}
+	err := writeOutputs(opts, func(w io.Writer, outputType string) error {
+		if changedBase != nil {
+			return gocloc.RenderWithBaseline(w, result, baseline, outputType, renderOpts)
 		}
-		switch o.opts.SortTag {
-		case "name":
-			sortedLanguages.SortByName()
-		case "files":
-			sortedLanguages.SortByFiles()
-		case "comment":
-			sortedLanguages.SortByComments()
-		case "blank":
-			sortedLanguages.SortByBlanks()
-		default:
-			sortedLanguages.SortByCode()
+		if baseline != nil {
+			return gocloc.RenderBaseline(w, baseline, outputType, renderOpts)
 		}
+		return gocloc.Render(w, result, outputType, renderOpts)
+	})
+	if err != nil {
+		fmt.Printf("fail to write output. error: %v\n", err)
+		os.Exit(1)
+	}
+	checkGate(gate, result, baseline)
+}
 
-		switch o.opts.OutputType {
-		case OutputTypeClocXML:
-			xmlResult := gocloc.NewXMLResultFromCloc(total, sortedLanguages, gocloc.XMLResultWithLangs)
-			xmlResult.Encode()
-		case OutputTypeJSON:
-			jsonResult := gocloc.NewJSONLanguagesResultFromCloc(total, sortedLanguages)
-			buf, err := json.Marshal(jsonResult)
-			if err != nil {
-				fmt.Println(err)
-				panic("json marshal error")
-			}
-			os.Stdout.Write(buf)
-		case OutputTypeMarkdown:
-			for _, language := range sortedLanguages {
-				fmt.Printf("| %-20v |%21v |%11v |%13v |%8v |\n",
-					language.Name, len(language.Files), language.Blanks, language.Comments, language.Code)
-			}
-		default:
-			for _, language := range sortedLanguages {
-				fmt.Printf("%-27v %6v %14v %14v %14v\n",
-					language.Name, len(language.Files), language.Blanks, language.Comments, language.Code)
-			}
-		}
+// checkGate prints the violations of the gate by the result and the baseline to the standard error,
+// and exits with status 1 if there are any.
+func checkGate(gate *gocloc.Gate, result *gocloc.Result, baseline *gocloc.BaselineResult) {
+	if gate == nil {
+		return
+	}
+	violations := gate.Check(result)
+	if baseline != nil {
+		violations = append(violations, gate.CheckBaseline(baseline)...)
+	}
+	if len(violations) == 0 {
+		return
+	}
+	fmt.Fprintln(os.Stderr, "gocloc gate failed:")
+	for _, v := range violations {
+		fmt.Fprintf(os.Stderr, "  %s\n", v)
 	}
+	os.Exit(1)
+}
 
-	// write footer
-	o.WriteFooter()
+// loadLanguageDefinitions reads the language definition file and merges it into languages,
+// or returns its languages only when languages is nil.
+func loadLanguageDefinitions(filename string, languages *gocloc.DefinedLanguages) (*gocloc.DefinedLanguages, error) {
+	fp, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+
+	if languages == nil {
+		return gocloc.LoadDefinedLanguages(fp)
+	}
+	if err := languages.Load(fp); err != nil {
+		return nil, err
+	}
+	return languages, nil
 }
 
 func main() {
@@ -281,14 +290,35 @@ func main() {
 	parser := flags.NewParser(&opts, flags.Default)
 	parser.Name = "gocloc"
 	parser.Usage = "[OPTIONS] PATH[...]"
-
-	paths, err := flags.Parse(&opts)
+	parser.SubcommandsOptional = true
+	diffCmd, _ := parser.AddCommand("diff",
+		"compare two source trees",
+		"Count the same, modified, added and removed lines between the OLD and NEW source trees.",
+		&struct{}{})
+
+	sumCmd, _ := parser.AddCommand("sum",
+		"sum saved reports",
+		"Merge the gocloc json, cloc-json, cloc-xml, yaml, csv, tsv and sql REPORTs by language and by file into one report.",
+		&struct{}{})
+
+	paths, err := parser.Parse()
 	if err != nil {
 		return
 	}
+	isDiff := parser.Active == diffCmd
+	isSum := parser.Active == sumCmd
 
 	// value for language result
 	languages := gocloc.NewDefinedLanguages()
+	if opts.ForceLangDef != "" {
+		languages, err = loadLanguageDefinitions(opts.ForceLangDef, nil)
+	} else if opts.ReadLangDef != "" {
+		languages, err = loadLanguageDefinitions(opts.ReadLangDef, languages)
+	}
+	if err != nil {
+		fmt.Printf("fail to read language definitions. error: %v\n", err)
+		os.Exit(1)
+	}
 
 	if opts.ShowVersion {
 		fmt.Printf("%s (%s)\n", Version, GitCommit)
@@ -304,16 +334,32 @@ func main() {
 		parser.WriteHelp(os.Stdout)
 		return
 	}
+	if isDiff && len(paths) != 2 {
+		fmt.Println("`diff` command requires two paths: OLD NEW")
+		os.Exit(1)
+	}
 
 	// check sort tag option with other options
-	if opts.ByFile && opts.SortTag == "files" {
+	if (opts.ByFile || opts.ByFileByLang) && opts.SortTag == "files" {
 		fmt.Println("`--sort files` option cannot be used in conjunction with the `--by-file` option")
 		os.Exit(1)
 	}
+	if (opts.ByFile || opts.ByFileByLang) && opts.ByDir {
+		fmt.Println("`--by-dir` option cannot be used in conjunction with the `--by-file` and `--by-file-by-lang` options")
+		os.Exit(1)
+	}
+	if opts.ChangedSince != "" && (isDiff || isSum || opts.GitRef != "" || opts.Baseline != "") {
+		fmt.Println("`--changed-since` option cannot be used in conjunction with the `--git-ref` and `--baseline` options and the commands")
+		os.Exit(1)
+	}
+	if utf8.RuneCountInString(opts.CSVDelimiter) != 1 {
+		fmt.Println("`--csv-delimiter` option must be a single character")
+		os.Exit(1)
+	}
 
 	// setup option for exclude extensions
 	for _, ext := range strings.Split(opts.ExcludeExt, ",") {
-		e, ok := gocloc.Exts[ext]
+		e, ok := languages.LanguageOfExt(ext)
 		if ok {
 			clocOpts.ExcludeExts[e] = struct{}{}
 		} else {
@@ -345,14 +391,84 @@ func main() {
 	clocOpts.Debug = opts.Debug
 	clocOpts.SkipDuplicated = opts.SkipDuplicated
 	clocOpts.Fullpath = opts.Fullpath
+	clocOpts.NoIgnore = opts.NoIgnore
+	clocOpts.IgnoreFiles = opts.IgnoreFile
+	clocOpts.Workers = opts.Jobs
+	clocOpts.DocstringAsCode = opts.DocstringAsCode
+
+	renderOpts := &gocloc.RenderOptions{
+		ByFile:       opts.ByFile,
+		ByDir:        opts.ByDir,
+		ByFileByLang: opts.ByFileByLang,
+		DirDepth:     opts.ByDirDepth,
+		SortTag:      opts.SortTag,
+		Version:      Version,
+		Paths:        paths,
+		SQLProject:   opts.SQLProject,
+		SQLAppend:    opts.SQLAppend,
+	}
+	renderOpts.CSVDelimiter, _ = utf8.DecodeRuneInString(opts.CSVDelimiter)
+	if opts.Template != "" {
+		text, err := os.ReadFile(opts.Template)
+		if err != nil {
+			fmt.Printf("fail to read template. error: %v\n", err)
+			os.Exit(1)
+		}
+		renderOpts.Template = string(text)
+	}
+	gate, err := loadGate(&opts)
+	if err != nil {
+		fmt.Printf("fail to read gate. error: %v\n", err)
+		os.Exit(1)
+	}
+	if gate != nil && opts.Baseline == "" && opts.ChangedSince == "" && hasGrowthBudget(gate) {
+		fmt.Println("`--max-code-growth` option requires the `--baseline` or `--changed-since` option")
+		os.Exit(1)
+	}
+
+	if isSum {
+		var results []*gocloc.Result
+		for _, path := range paths {
+			result, err := readReport(path)
+			if err != nil {
+				fmt.Printf("fail to read report. error: %v\n", err)
+				os.Exit(1)
+			}
+			results = append(results, result)
+		}
+		writeResult(&opts, gocloc.MergeResults(results...), nil, renderOpts, gate)
+		return
+	}
 
 	processor := gocloc.NewProcessor(languages, clocOpts)
-	result, err := processor.Analyze(paths)
+	if isDiff {
+		result, err := processor.Diff(paths[:1], paths[1:])
+		if err != nil {
+			fmt.Printf("fail gocloc diff. error: %v\n", err)
+			os.Exit(1)
+		}
+		err = writeOutputs(&opts, func(w io.Writer, outputType string) error {
+			return gocloc.RenderDiff(w, result, outputType, renderOpts)
+		})
+		if err != nil {
+			fmt.Printf("fail to write output. error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	var result, changedBase *gocloc.Result
+	if opts.ChangedSince != "" {
+		result, changedBase, err = processor.AnalyzeChangedSince(opts.ChangedSince, paths, opts.Untracked)
+	} else if opts.GitRef != "" {
+		result, err = processor.AnalyzeGitRef(opts.GitRef, paths)
+	} else {
+		result, err = processor.Analyze(paths)
+	}
 	if err != nil {
 		fmt.Printf("fail gocloc analyze. error: %v\n", err)
-		return
+		os.Exit(1)
 	}
 
-	builder := newOutputBuilder(result, &opts)
-	builder.WriteResult()
+	writeResult(&opts, result, changedBase, renderOpts, gate)
 }
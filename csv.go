@@ -0,0 +1,78 @@
+package gocloc
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// CSVResult defines the result of the analysis in CSV format,
+// with the columns of cloc's --csv option.
+type CSVResult struct {
+	Header []string
+	Rows   [][]string
+}
+
+func formatCounts(counts ...int32) []string {
+	fields := make([]string, len(counts))
+	for i, c := range counts {
+		fields[i] = strconv.FormatInt(int64(c), 10)
+	}
+	return fields
+}
+
+// NewCSVLanguagesResultFromCloc returns CSVResult with a row for each language and the sum.
+func NewCSVLanguagesResultFromCloc(total *Language, sortedLanguages Languages) *CSVResult {
+	result := &CSVResult{
+		Header: []string{"files", "language", "blank", "comment", "code"},
+	}
+	for _, language := range sortedLanguages {
+		counts := formatCounts(language.FilesCount(), language.Blanks, language.Comments, language.Code)
+		result.Rows = append(result.Rows, []string{counts[0], language.Name, counts[1], counts[2], counts[3]})
+	}
+	counts := formatCounts(total.Total, total.Blanks, total.Comments, total.Code)
+	result.Rows = append(result.Rows, []string{counts[0], "SUM", counts[1], counts[2], counts[3]})
+	return result
+}
+
+// NewCSVDirectoriesResultFromCloc returns CSVResult with a row for each directory and the sum.
+func NewCSVDirectoriesResultFromCloc(total *Language, sortedDirectories ClocDirectories) *CSVResult {
+	result := &CSVResult{
+		Header: []string{"files", "directory", "blank", "comment", "code"},
+	}
+	for _, dir := range sortedDirectories {
+		counts := formatCounts(dir.FilesCount, dir.Blanks, dir.Comments, dir.Code)
+		result.Rows = append(result.Rows, []string{counts[0], dir.Name, counts[1], counts[2], counts[3]})
+	}
+	counts := formatCounts(total.Total, total.Blanks, total.Comments, total.Code)
+	result.Rows = append(result.Rows, []string{counts[0], "SUM", counts[1], counts[2], counts[3]})
+	return result
+}
+
+// NewCSVFilesResultFromCloc returns CSVResult with a row for each file and the sum.
+func NewCSVFilesResultFromCloc(total *Language, sortedFiles ClocFiles) *CSVResult {
+	result := &CSVResult{
+		Header: []string{"language", "filename", "blank", "comment", "code"},
+	}
+	for _, file := range sortedFiles {
+		row := append([]string{file.Lang, file.Name}, formatCounts(file.Blanks, file.Comments, file.Code)...)
+		result.Rows = append(result.Rows, row)
+	}
+	row := append([]string{"SUM", ""}, formatCounts(total.Blanks, total.Comments, total.Code)...)
+	result.Rows = append(result.Rows, row)
+	return result
+}
+
+// Encode writes the header and the rows separated by delimiter,
+// quoting the fields which contain the delimiter, quotes or line breaks.
+func (c *CSVResult) Encode(w io.Writer, delimiter rune) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = delimiter
+	if err := writer.Write(c.Header); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(c.Rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
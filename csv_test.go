@@ -0,0 +1,66 @@
+package gocloc
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+)
+
+func TestCSVLanguagesResult(t *testing.T) {
+	total := &Language{Total: 3, Code: 30, Comments: 6, Blanks: 4}
+	langs := Languages{
+		{Name: "Go", Files: []string{"a.go", "b.go"}, Code: 20, Comments: 5, Blanks: 3},
+		{Name: "C", Files: []string{"c.c"}, Code: 10, Comments: 1, Blanks: 1},
+	}
+
+	var buf bytes.Buffer
+	if err := NewCSVLanguagesResultFromCloc(total, langs).Encode(&buf, ','); err != nil {
+		t.Fatalf("Encode() error. err=[%v]", err)
+	}
+	expected := "files,language,blank,comment,code\n2,Go,3,5,20\n1,C,1,1,10\n3,SUM,4,6,30\n"
+	if buf.String() != expected {
+		t.Errorf("invalid logic. csv=%q", buf.String())
+	}
+}
+
+func TestCSVFilesResult(t *testing.T) {
+	total := &Language{Total: 3, Code: 6, Comments: 3, Blanks: 0}
+	files := ClocFiles{
+		{Name: `src/a,b.go`, Lang: "Go", Code: 1, Comments: 1},
+		{Name: `src/"quoted".go`, Lang: "Go", Code: 2, Comments: 1},
+		{Name: "src/tab\tname.go", Lang: "Go", Code: 3, Comments: 1},
+	}
+
+	for _, delimiter := range []rune{',', '\t', ';'} {
+		var buf bytes.Buffer
+		if err := NewCSVFilesResultFromCloc(total, files).Encode(&buf, delimiter); err != nil {
+			t.Fatalf("Encode() error. err=[%v]", err)
+		}
+
+		reader := csv.NewReader(&buf)
+		reader.Comma = delimiter
+		records, err := reader.ReadAll()
+		if err != nil {
+			t.Fatalf("ReadAll() error. delimiter=%q err=[%v]", delimiter, err)
+		}
+		if len(records) != 5 {
+			t.Fatalf("invalid logic. delimiter=%q records=%v", delimiter, records)
+		}
+		if records[0][1] != "filename" {
+			t.Errorf("invalid logic. header=%v", records[0])
+		}
+		for i, file := range files {
+			if records[i+1][1] != file.Name {
+				t.Errorf("invalid logic. delimiter=%q name=%v", delimiter, records[i+1][1])
+			}
+		}
+		if sum := records[4]; sum[0] != "SUM" || sum[4] != "6" {
+			t.Errorf("invalid logic. sum=%v", sum)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := NewCSVFilesResultFromCloc(total, files).Encode(&buf, '"'); err == nil {
+		t.Errorf("invalid logic. quote delimiter should be an error")
+	}
+}
@@ -0,0 +1,417 @@
+package gocloc
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+)
+
+// DiffStatus is the status of a file in a diff.
+type DiffStatus string
+
+const (
+	// DiffSame is the status of the files and lines found unchanged in both trees
+	DiffSame DiffStatus = "same"
+	// DiffModified is the status of the files and lines changed between the trees
+	DiffModified DiffStatus = "modified"
+	// DiffAdded is the status of the files and lines found only in the new tree
+	DiffAdded DiffStatus = "added"
+	// DiffRemoved is the status of the files and lines found only in the old tree
+	DiffRemoved DiffStatus = "removed"
+)
+
+// DiffStatuses is the list of all statuses in the order of the reports.
+var DiffStatuses = []DiffStatus{DiffSame, DiffModified, DiffAdded, DiffRemoved}
+
+// DiffLines is the number of lines of one status in a diff.
+type DiffLines struct {
+	Code     int32 `xml:"code,attr" json:"code"`
+	Comments int32 `xml:"comment,attr" json:"comment"`
+	Blanks   int32 `xml:"blank,attr" json:"blank"`
+}
+
+// DiffFile is the diff result of one file.
+type DiffFile struct {
+	Name     string     `json:"name"`
+	Lang     string     `json:"language"`
+	Status   DiffStatus `json:"status"`
+	Same     DiffLines  `json:"same"`
+	Modified DiffLines  `json:"modified"`
+	Added    DiffLines  `json:"added"`
+	Removed  DiffLines  `json:"removed"`
+}
+
+// Lines returns the number of lines of the status.
+func (f *DiffFile) Lines(status DiffStatus) *DiffLines {
+	switch status {
+	case DiffModified:
+		return &f.Modified
+	case DiffAdded:
+		return &f.Added
+	case DiffRemoved:
+		return &f.Removed
+	default:
+		return &f.Same
+	}
+}
+
+// DiffFiles is an array representation of DiffFile.
+type DiffFiles []DiffFile
+
+func (df DiffFiles) SortByName() {
+	sortFunc := func(i, j int) bool {
+		return df[i].Name < df[j].Name
+	}
+	sort.Slice(df, sortFunc)
+}
+
+// DiffLanguage is the diff result of one programming language.
+// FilesCount of each status is the number of files having this status.
+type DiffLanguage struct {
+	Name     string       `json:"name,omitempty"`
+	Same     ClocLanguage `json:"same"`
+	Modified ClocLanguage `json:"modified"`
+	Added    ClocLanguage `json:"added"`
+	Removed  ClocLanguage `json:"removed"`
+}
+
+// Stats returns the numbers of files and lines of the status.
+func (l *DiffLanguage) Stats(status DiffStatus) *ClocLanguage {
+	switch status {
+	case DiffModified:
+		return &l.Modified
+	case DiffAdded:
+		return &l.Added
+	case DiffRemoved:
+		return &l.Removed
+	default:
+		return &l.Same
+	}
+}
+
+func (l *DiffLanguage) add(file *DiffFile) {
+	for _, status := range DiffStatuses {
+		stats, lines := l.Stats(status), file.Lines(status)
+		stats.Code += lines.Code
+		stats.Comments += lines.Comments
+		stats.Blanks += lines.Blanks
+	}
+	l.Stats(file.Status).FilesCount++
+}
+
+// DiffLanguages is an array representation of DiffLanguage.
+type DiffLanguages []DiffLanguage
+
+func (dl DiffLanguages) SortByName() {
+	sortFunc := func(i, j int) bool {
+		return dl[i].Name < dl[j].Name
+	}
+	sort.Slice(dl, sortFunc)
+}
+
+// DiffResult defined diff processing result.
+type DiffResult struct {
+	Total         *DiffLanguage
+	Files         map[string]*DiffFile
+	Languages     map[string]*DiffLanguage
+	MaxPathLength int
+}
+
+// diffSource is a file of one side of a diff with its classified lines.
+type diffSource struct {
+	path  string
+	lang  string
+	lines []lineEvent
+}
+
+// Diff compares the source trees of oldPaths and newPaths, and returns the
+// number of same, modified, added and removed lines per file and language.
+// The paths are paired by position, and the files of each pair are paired
+// by their path relative to the root of the tree.
+func (p *Processor) Diff(oldPaths, newPaths []string) (*DiffResult, error) {
+	if len(oldPaths) != len(newPaths) {
+		return nil, fmt.Errorf("number of paths mismatch. old=%d, new=%d", len(oldPaths), len(newPaths))
+	}
+
+	oldSources, oldKeys, err := p.collectDiffSources(oldPaths)
+	if err != nil {
+		return nil, err
+	}
+	newSources, newKeys, err := p.collectDiffSources(newPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DiffResult{
+		Total:     &DiffLanguage{Name: "TOTAL"},
+		Files:     make(map[string]*DiffFile),
+		Languages: make(map[string]*DiffLanguage),
+	}
+	addFile := func(file *DiffFile) {
+		language, ok := result.Languages[file.Lang]
+		if !ok {
+			language = &DiffLanguage{Name: file.Lang}
+			result.Languages[file.Lang] = language
+		}
+		language.add(file)
+		result.Total.add(file)
+		result.Files[file.Name] = file
+		if l := len(file.Name); result.MaxPathLength < l {
+			result.MaxPathLength = l
+		}
+	}
+
+	for _, key := range newKeys {
+		newSource := newSources[key]
+		oldSource, ok := oldSources[key]
+		switch {
+		case !ok:
+			addFile(diffOneSide(newSource, DiffAdded))
+		case oldSource.lang != newSource.lang:
+			addFile(diffOneSide(oldSource, DiffRemoved))
+			addFile(diffOneSide(newSource, DiffAdded))
+		default:
+			addFile(diffSources(oldSource, newSource))
+		}
+	}
+	for _, key := range oldKeys {
+		if _, ok := newSources[key]; !ok {
+			addFile(diffOneSide(oldSources[key], DiffRemoved))
+		}
+	}
+
+	return result, nil
+}
+
+// collectDiffSources analyzes the paths and returns the files keyed by the
+// position of their root and their path relative to it, with the keys in walking order.
+func (p *Processor) collectDiffSources(paths []string) (map[string]*diffSource, []string, error) {
+	rootIndex := make(map[string]int, len(paths))
+	for i := len(paths) - 1; i >= 0; i-- {
+		rootIndex[paths[i]] = i
+	}
+
+	// files with the same content are paired with their counterpart, not skipped
+	opts := *p.opts
+	opts.SkipDuplicated = true
+	opts.OnCode, opts.OnComment, opts.OnBlank = nil, nil, nil
+	processor := &Processor{langs: p.langs, opts: &opts, fsys: p.fsys}
+
+	sources := make(map[string]*diffSource)
+	var keys []string
+	_, err := processor.analyze(paths, func(r *analyzeResult) {
+		rel, err := filepath.Rel(r.root, r.path)
+		if r.entry != "" {
+			rel = r.entry
+		} else if err != nil || rel == "." {
+			rel = filepath.Base(r.path)
+		}
+		key := fmt.Sprintf("%d:%s", rootIndex[r.root], filepath.ToSlash(rel))
+		if _, ok := sources[key]; !ok {
+			keys = append(keys, key)
+		}
+		sources[key] = &diffSource{
+			path:  r.path,
+			lang:  r.file.Lang,
+			lines: r.events,
+		}
+	})
+	return sources, keys, err
+}
+
+// diffOneSide returns the diff of a file found only in one of the trees.
+func diffOneSide(source *diffSource, status DiffStatus) *DiffFile {
+	file := &DiffFile{
+		Name:   source.path,
+		Lang:   source.lang,
+		Status: status,
+	}
+	lines := file.Lines(status)
+	for _, line := range source.lines {
+		switch line.kind {
+		case lineCode:
+			lines.Code++
+		case lineComment:
+			lines.Comments++
+		case lineBlank:
+			lines.Blanks++
+		}
+	}
+	return file
+}
+
+// diffSources compares the lines of each kind between two versions of a file.
+func diffSources(oldSource, newSource *diffSource) *DiffFile {
+	file := &DiffFile{
+		Name:   newSource.path,
+		Lang:   newSource.lang,
+		Status: DiffSame,
+	}
+
+	for _, kind := range []lineKind{lineCode, lineComment, lineBlank} {
+		same, modified, added, removed := diffLines(
+			linesOfKind(oldSource.lines, kind), linesOfKind(newSource.lines, kind))
+		counts := map[DiffStatus]int32{
+			DiffSame:     same,
+			DiffModified: modified,
+			DiffAdded:    added,
+			DiffRemoved:  removed,
+		}
+		for status, n := range counts {
+			lines := file.Lines(status)
+			switch kind {
+			case lineCode:
+				lines.Code = n
+			case lineComment:
+				lines.Comments = n
+			case lineBlank:
+				lines.Blanks = n
+			}
+		}
+		if modified+added+removed > 0 {
+			file.Status = DiffModified
+		}
+	}
+	return file
+}
+
+func linesOfKind(lines []lineEvent, kind lineKind) []string {
+	var ret []string
+	for _, line := range lines {
+		if line.kind == kind {
+			ret = append(ret, line.line)
+		}
+	}
+	return ret
+}
+
+// diffLines compares two sequences of lines. Lines are same when they are
+// part of the longest common subsequence. Between two common lines, removed
+// and added lines are paired as modified lines.
+func diffLines(oldLines, newLines []string) (same, modified, added, removed int32) {
+	ids := make(map[string]int)
+	intern := func(lines []string) []int {
+		ret := make([]int, len(lines))
+		for i, line := range lines {
+			id, ok := ids[line]
+			if !ok {
+				id = len(ids)
+				ids[line] = id
+			}
+			ret[i] = id
+		}
+		return ret
+	}
+	a, b := intern(oldLines), intern(newLines)
+
+	prevA, prevB := -1, -1
+	hunk := func(nextA, nextB int) {
+		del, ins := int32(nextA-prevA-1), int32(nextB-prevB-1)
+		m := del
+		if ins < m {
+			m = ins
+		}
+		modified += m
+		removed += del - m
+		added += ins - m
+	}
+	diffMatches(a, b, 0, 0, func(i, j int) {
+		hunk(i, j)
+		same++
+		prevA, prevB = i, j
+	})
+	hunk(len(a), len(b))
+	return
+}
+
+// diffMatches calls emit in order for every pair of matched elements of the
+// longest common subsequence of a and b, using the linear space variation
+// of Myers' O(ND) difference algorithm.
+func diffMatches(a, b []int, offA, offB int, emit func(i, j int)) {
+	// common prefix
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		emit(offA, offB)
+		a, b = a[1:], b[1:]
+		offA, offB = offA+1, offB+1
+	}
+	// common suffix
+	suffix := 0
+	for suffix < len(a) && suffix < len(b) && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	a, b = a[:len(a)-suffix], b[:len(b)-suffix]
+
+	if len(a) > 0 && len(b) > 0 {
+		x, y, u, v := middleSnake(a, b)
+		diffMatches(a[:x], b[:y], offA, offB, emit)
+		for k := 0; k < u-x; k++ {
+			emit(offA+x+k, offB+y+k)
+		}
+		diffMatches(a[u:], b[v:], offA+u, offB+v, emit)
+	}
+
+	for k := 0; k < suffix; k++ {
+		emit(offA+len(a)+k, offB+len(b)+k)
+	}
+}
+
+// middleSnake returns the middle snake (x, y)-(u, v) of an optimal edit script of a and b.
+func middleSnake(a, b []int) (x, y, u, v int) {
+	n, m := len(a), len(b)
+	maxD := n + m
+	delta := n - m
+	offset := maxD + 1
+	forward := make([]int, 2*maxD+3)
+	backward := make([]int, 2*maxD+3)
+
+	for d := 0; d <= (maxD+1)/2; d++ {
+		// forward paths
+		for k := -d; k <= d; k += 2 {
+			var px int
+			if k == -d || (k != d && forward[offset+k-1] < forward[offset+k+1]) {
+				px = forward[offset+k+1]
+			} else {
+				px = forward[offset+k-1] + 1
+			}
+			py := px - k
+			sx, sy := px, py
+			for px < n && py < m && a[px] == b[py] {
+				px, py = px+1, py+1
+			}
+			forward[offset+k] = px
+
+			// overlap with the backward path of d-1 on the same diagonal
+			if kb := delta - k; delta%2 != 0 && kb >= -(d-1) && kb <= d-1 {
+				if px+backward[offset+kb] >= n {
+					return sx, sy, px, py
+				}
+			}
+		}
+
+		// backward paths, on the reversed sequences
+		for k := -d; k <= d; k += 2 {
+			var px int
+			if k == -d || (k != d && backward[offset+k-1] < backward[offset+k+1]) {
+				px = backward[offset+k+1]
+			} else {
+				px = backward[offset+k-1] + 1
+			}
+			py := px - k
+			sx, sy := px, py
+			for px < n && py < m && a[n-1-px] == b[m-1-py] {
+				px, py = px+1, py+1
+			}
+			backward[offset+k] = px
+
+			// overlap with the forward path of d on the same diagonal
+			if kf := delta - k; delta%2 == 0 && kf >= -d && kf <= d {
+				if px+forward[offset+kf] >= n {
+					return n - px, m - py, n - sx, m - sy
+				}
+			}
+		}
+	}
+
+	// not reached for valid inputs
+	return 0, 0, 0, 0
+}
@@ -0,0 +1,143 @@
+package gocloc
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		oldLines string
+		newLines string
+		same     int32
+		modified int32
+		added    int32
+		removed  int32
+	}{
+		{"", "", 0, 0, 0, 0},
+		{"a b c", "a b c", 3, 0, 0, 0},
+		{"", "a b", 0, 0, 2, 0},
+		{"a b", "", 0, 0, 0, 2},
+		{"a b c", "a x c", 2, 1, 0, 0},
+		{"a b c", "a x y c", 2, 1, 1, 0},
+		{"a b c d", "a x d", 2, 1, 0, 1},
+		{"a b c a b b a", "c b a b a c", 4, 1, 1, 2},
+		{"x a y b z", "a b", 2, 0, 0, 3},
+	}
+
+	for _, tt := range tests {
+		same, modified, added, removed := diffLines(strings.Fields(tt.oldLines), strings.Fields(tt.newLines))
+		if same != tt.same || modified != tt.modified || added != tt.added || removed != tt.removed {
+			t.Errorf("invalid logic. old=[%v] new=[%v] same=%v modified=%v added=%v removed=%v",
+				tt.oldLines, tt.newLines, same, modified, added, removed)
+		}
+	}
+}
+
+func TestDiffMatchesLongestCommonSubsequence(t *testing.T) {
+	a := make([]int, 300)
+	b := make([]int, 250)
+	for i := range a {
+		a[i] = (i * 7) % 13
+	}
+	for i := range b {
+		b[i] = (i * 5) % 11
+	}
+
+	// reference length by dynamic programming
+	dp := make([][]int, len(a)+1)
+	for i := range dp {
+		dp[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				dp[i][j] = dp[i+1][j+1] + 1
+			} else if dp[i+1][j] > dp[i][j+1] {
+				dp[i][j] = dp[i+1][j]
+			} else {
+				dp[i][j] = dp[i][j+1]
+			}
+		}
+	}
+
+	matches := 0
+	prevI, prevJ := -1, -1
+	diffMatches(a, b, 0, 0, func(i, j int) {
+		if i <= prevI || j <= prevJ || a[i] != b[j] {
+			t.Fatalf("invalid logic. match=(%v, %v) after (%v, %v)", i, j, prevI, prevJ)
+		}
+		prevI, prevJ = i, j
+		matches++
+	})
+	if matches != dp[0][0] {
+		t.Errorf("invalid logic. matches=%v, lcs=%v", matches, dp[0][0])
+	}
+}
+
+func TestProcessorDiff(t *testing.T) {
+	oldRoot := writeTestTree(t, map[string]string{
+		"same.go":     "package main\n\n// same\nvar a = 1\n",
+		"modified.go": "package main\n\n// old comment\nvar a = 1\nvar b = 2\nvar c = 3\n",
+		"removed.py":  "# removed\nx = 1\n",
+		"sub/a.go":    "package sub\n",
+	})
+	newRoot := writeTestTree(t, map[string]string{
+		"same.go":     "package main\n\n// same\nvar a = 1\n",
+		"modified.go": "package main\n\n\n// new comment\nvar a = 1\nvar b = 20\n",
+		"added.py":    "# added\nx = 1\ny = 2\n",
+		"sub/a.go":    "package sub\n",
+	})
+
+	processor := NewProcessor(NewDefinedLanguages(), NewClocOptions())
+	result, err := processor.Diff([]string{oldRoot}, []string{newRoot})
+	if err != nil {
+		t.Fatalf("Diff() error. err=[%v]", err)
+	}
+
+	modified := result.Files[filepath.Join(newRoot, "modified.go")]
+	if modified == nil || modified.Status != DiffModified {
+		t.Fatalf("invalid logic. modified=%+v", modified)
+	}
+	expected := DiffFile{
+		Name:     filepath.Join(newRoot, "modified.go"),
+		Lang:     "Go",
+		Status:   DiffModified,
+		Same:     DiffLines{Code: 2, Blanks: 1},
+		Modified: DiffLines{Code: 1, Comments: 1},
+		Added:    DiffLines{Blanks: 1},
+		Removed:  DiffLines{Code: 1},
+	}
+	if *modified != expected {
+		t.Errorf("invalid logic. modified=%+v", modified)
+	}
+
+	golang := result.Languages["Go"]
+	if golang.Same.FilesCount != 2 || golang.Modified.FilesCount != 1 {
+		t.Errorf("invalid logic. go=%+v", golang)
+	}
+	python := result.Languages["Python"]
+	if python.Added.FilesCount != 1 || python.Added.Code != 2 || python.Added.Comments != 1 {
+		t.Errorf("invalid logic. python added=%+v", python.Added)
+	}
+	if python.Removed.FilesCount != 1 || python.Removed.Code != 1 || python.Removed.Comments != 1 {
+		t.Errorf("invalid logic. python removed=%+v", python.Removed)
+	}
+	if result.Files[filepath.Join(oldRoot, "removed.py")].Status != DiffRemoved {
+		t.Errorf("invalid logic. removed.py is not removed")
+	}
+
+	total := fmt.Sprintf("%+v", *result.Total)
+	if result.Total.Same.FilesCount != 2 || result.Total.Same.Code != 5 || result.Total.Added.Code != 2 {
+		t.Errorf("invalid logic. total=%v", total)
+	}
+}
+
+func TestProcessorDiffMismatchPaths(t *testing.T) {
+	processor := NewProcessor(NewDefinedLanguages(), NewClocOptions())
+	if _, err := processor.Diff([]string{"a", "b"}, []string{"c"}); err == nil {
+		t.Errorf("invalid logic. mismatch paths should be error")
+	}
+}
@@ -0,0 +1,151 @@
+package gocloc
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ClocDirectory is the total of the files grouped in a directory.
+type ClocDirectory struct {
+	Name       string
+	FilesCount int32
+	Code       int32
+	Comments   int32
+	Blanks     int32
+	Files      ClocFiles
+}
+
+// ClocDirectories is a set of directory totals.
+type ClocDirectories []ClocDirectory
+
+// NewClocDirectories groups the files by directory.
+// The directories are sorted by name, and the files keep their order.
+func NewClocDirectories(files ClocFiles) ClocDirectories {
+	return NewClocDirectoriesByDepth(files, 0)
+}
+
+// NewClocDirectoriesByDepth groups the files by their directory cut to depth path elements
+// below the analyzed root containing it, so that each directory holds the files of its
+// subdirectories deeper than depth. The elements of a file outside the roots are counted
+// from the start of its path. A depth of 0 or less groups the files by the directory they are in.
+// The directories are sorted by name, and the files keep their order.
+func NewClocDirectoriesByDepth(files ClocFiles, depth int, roots ...string) ClocDirectories {
+	index := make(map[string]int)
+	var dirs ClocDirectories
+	for _, file := range files {
+		name := dirAtDepth(filepath.Dir(file.Name), depth, roots)
+		i, ok := index[name]
+		if !ok {
+			i = len(dirs)
+			index[name] = i
+			dirs = append(dirs, ClocDirectory{Name: name})
+		}
+		dir := &dirs[i]
+		dir.FilesCount++
+		dir.Code += file.Code
+		dir.Comments += file.Comments
+		dir.Blanks += file.Blanks
+		dir.Files = append(dir.Files, file)
+	}
+	dirs.SortByName()
+	return dirs
+}
+
+// dirAtDepth returns dir cut to depth path elements below the longest root containing it,
+// or to the first depth path elements of dir if no root contains it.
+func dirAtDepth(dir string, depth int, roots []string) string {
+	if depth <= 0 {
+		return dir
+	}
+	var base, rel string
+	for _, root := range roots {
+		r, err := filepath.Rel(root, dir)
+		if err != nil || r == ".." || strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if base == "" || len(root) > len(base) {
+			base, rel = root, r
+		}
+	}
+	if base == "" {
+		return cutPath(dir, depth)
+	}
+	if rel == "." {
+		return dir
+	}
+	return filepath.Join(base, cutPath(rel, depth))
+}
+
+// cutPath returns the first depth path elements of dir.
+func cutPath(dir string, depth int) string {
+	slashed := filepath.ToSlash(dir)
+	begin := 0
+	if strings.HasPrefix(slashed, "/") {
+		// the root is not an element
+		begin = 1
+	}
+	for i := begin; i < len(slashed); i++ {
+		if slashed[i] == '/' {
+			depth--
+			if depth == 0 {
+				return dir[:i]
+			}
+		}
+	}
+	return dir
+}
+
+// ClocLanguages returns the totals of the directories in the form of ClocLanguage.
+func (cd ClocDirectories) ClocLanguages() []ClocLanguage {
+	var langs []ClocLanguage
+	for _, dir := range cd {
+		langs = append(langs, ClocLanguage{
+			Name:       dir.Name,
+			FilesCount: dir.FilesCount,
+			Code:       dir.Code,
+			Comments:   dir.Comments,
+			Blanks:     dir.Blanks,
+		})
+	}
+	return langs
+}
+
+func (cd ClocDirectories) SortByName() {
+	sort.Slice(cd, func(i, j int) bool {
+		return cd[i].Name < cd[j].Name
+	})
+}
+
+func (cd ClocDirectories) SortByFiles() {
+	sort.SliceStable(cd, func(i, j int) bool {
+		if cd[i].FilesCount == cd[j].FilesCount {
+			return cd[i].Code > cd[j].Code
+		}
+		return cd[i].FilesCount > cd[j].FilesCount
+	})
+}
+
+func (cd ClocDirectories) SortByComments() {
+	sort.SliceStable(cd, func(i, j int) bool {
+		if cd[i].Comments == cd[j].Comments {
+			return cd[i].Code > cd[j].Code
+		}
+		return cd[i].Comments > cd[j].Comments
+	})
+}
+
+func (cd ClocDirectories) SortByBlanks() {
+	sort.SliceStable(cd, func(i, j int) bool {
+		if cd[i].Blanks == cd[j].Blanks {
+			return cd[i].Code > cd[j].Code
+		}
+		return cd[i].Blanks > cd[j].Blanks
+	})
+}
+
+func (cd ClocDirectories) SortByCode() {
+	sort.SliceStable(cd, func(i, j int) bool {
+		return cd[i].Code > cd[j].Code
+	})
+}
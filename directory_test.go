@@ -0,0 +1,97 @@
+package gocloc
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewClocDirectories(t *testing.T) {
+	files := ClocFiles{
+		{Name: filepath.Join("src", "b.go"), Code: 10, Comments: 2, Blanks: 1},
+		{Name: "main.go", Code: 5},
+		{Name: filepath.Join("src", "a.go"), Code: 3, Comments: 1},
+	}
+	dirs := NewClocDirectories(files)
+	if len(dirs) != 2 || dirs[0].Name != "." || dirs[1].Name != "src" {
+		t.Fatalf("invalid logic. dirs=%+v", dirs)
+	}
+	src := dirs[1]
+	if src.FilesCount != 2 || src.Code != 13 || src.Comments != 3 || src.Blanks != 1 {
+		t.Errorf("invalid logic. src=%+v", src)
+	}
+	if src.Files[0].Name != files[0].Name || src.Files[1].Name != files[2].Name {
+		t.Errorf("invalid logic. files=%v", src.Files)
+	}
+}
+
+func TestNewClocDirectoriesByDepth(t *testing.T) {
+	files := ClocFiles{
+		{Name: filepath.Join("services", "payments", "api", "a.go"), Code: 10},
+		{Name: filepath.Join("services", "payments", "b.go"), Code: 5},
+		{Name: filepath.Join("services", "search", "c.go"), Code: 7},
+		{Name: "main.go", Code: 1},
+	}
+	tests := []struct {
+		depth int
+		names []string
+		codes []int32
+	}{
+		{0, []string{".", filepath.Join("services", "payments"), filepath.Join("services", "payments", "api"), filepath.Join("services", "search")}, []int32{1, 5, 10, 7}},
+		{1, []string{".", "services"}, []int32{1, 22}},
+		{2, []string{".", filepath.Join("services", "payments"), filepath.Join("services", "search")}, []int32{1, 15, 7}},
+	}
+	for _, tt := range tests {
+		dirs := NewClocDirectoriesByDepth(files, tt.depth)
+		var names []string
+		var codes []int32
+		for _, dir := range dirs {
+			names = append(names, dir.Name)
+			codes = append(codes, dir.Code)
+		}
+		if !equalStrings(names, tt.names) || fmt.Sprint(codes) != fmt.Sprint(tt.codes) {
+			t.Errorf("invalid logic. depth=%v names=%v codes=%v", tt.depth, names, codes)
+		}
+	}
+}
+
+func TestDirAtDepth(t *testing.T) {
+	tests := []struct {
+		dir      string
+		depth    int
+		expected string
+	}{
+		{"a/b/c", 1, "a"},
+		{"a/b/c", 2, "a/b"},
+		{"a/b/c", 5, "a/b/c"},
+		{"vendor.tar!src/a", 1, "vendor.tar!src"},
+		{".", 1, "."},
+	}
+	for _, tt := range tests {
+		if dir := filepath.ToSlash(dirAtDepth(filepath.FromSlash(tt.dir), tt.depth, nil)); dir != tt.expected {
+			t.Errorf("invalid logic. dir=%v depth=%v result=%v", tt.dir, tt.depth, dir)
+		}
+	}
+
+	// the elements are counted below the root containing the directory
+	rootTests := []struct {
+		dir      string
+		depth    int
+		roots    []string
+		expected string
+	}{
+		{"/tmp/data/services/payments/api", 1, []string{"/tmp/data"}, "/tmp/data/services"},
+		{"/tmp/data/services/payments/api", 2, []string{"/tmp/data/"}, "/tmp/data/services/payments"},
+		{"/tmp/data", 1, []string{"/tmp/data"}, "/tmp/data"},
+		{"../cmd/gocloc", 1, []string{"../"}, "../cmd"},
+		{"services/search", 1, []string{"."}, "services"},
+		{"lib/a/b", 1, []string{".", "lib"}, "lib/a"},
+		{"/other/a/b", 1, []string{"/tmp/data"}, "/other"},
+	}
+	for _, tt := range rootTests {
+		dir := filepath.ToSlash(dirAtDepth(filepath.FromSlash(tt.dir), tt.depth, tt.roots))
+		if dir != tt.expected {
+			t.Errorf("invalid logic. dir=%v depth=%v roots=%v result=%v", tt.dir, tt.depth, tt.roots, dir)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package gocloc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WithDocstrings makes the language count its triple-quoted strings as comments
+// only when they are docstrings, i.e. the first statement of a module, class or function.
+// The multi-line comments of the language are not used in this mode.
+func (l *Language) WithDocstrings() *Language {
+	l.docstrings = true
+	return l
+}
+
+// docstringScanner counts the lines of Python-like languages.
+type docstringScanner struct {
+	clocFile *ClocFile
+	opts     *ClocOptions
+
+	// quote is the closing quotes of the open triple-quoted string.
+	quote string
+	// docstring is true if the open string is in docstring position.
+	docstring bool
+	// pending holds the original lines of the open docstring until it is closed.
+	pending []string
+
+	expectDocstring bool
+	inHeader        bool
+	depth           int
+	last            byte
+}
+
+func analyzeDocstringReader(clocFile *ClocFile, reader *bufio.Reader, opts *ClocOptions) {
+	s := &docstringScanner{
+		clocFile:        clocFile,
+		opts:            opts,
+		expectDocstring: true,
+	}
+
+	isFirstLine := true
+	for {
+		lineOrg, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			fmt.Printf("ERROR - could not read file (-> skip): %v\n", err)
+			break
+		}
+		if len(lineOrg) == 0 && err == io.EOF {
+			break
+		}
+
+		line := strings.TrimSpace(lineOrg)
+		if isFirstLine {
+			line = trimBOM(line)
+		}
+		s.scanLine(line, lineOrg, isFirstLine)
+		isFirstLine = false
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	// an unterminated docstring
+	s.flush(s.docstring)
+}
+
+func (s *docstringScanner) scanLine(line, lineOrg string, isFirstLine bool) {
+	if s.quote != "" && s.docstring {
+		s.pending = append(s.pending, lineOrg)
+		end, closed := s.skipString(line, 0)
+		if !closed {
+			return
+		}
+		s.quote = ""
+		if isStatementEnd(line[end:]) {
+			s.flush(true)
+			return
+		}
+		s.flush(false)
+		s.scanCode(line[end:])
+		s.endLine()
+		return
+	}
+
+	if len(line) == 0 {
+		onBlank(s.clocFile, s.opts, s.quote != "", line, lineOrg)
+		return
+	}
+
+	// the rest of a multi-line string is code
+	if s.quote != "" {
+		onCode(s.clocFile, s.opts, true, line, lineOrg)
+		if end, closed := s.skipString(line, 0); closed {
+			s.quote = ""
+			s.scanCode(line[end:])
+		}
+		s.endLine()
+		return
+	}
+
+	// shebang line is 'code'
+	if isFirstLine && strings.HasPrefix(line, "#!") {
+		onCode(s.clocFile, s.opts, false, line, lineOrg)
+		return
+	}
+	if line[0] == '#' {
+		onComment(s.clocFile, s.opts, false, line, lineOrg)
+		return
+	}
+
+	if s.expectDocstring && s.depth == 0 {
+		if quote, begin := docstringStart(line); begin > 0 {
+			s.expectDocstring = false
+			s.quote, s.docstring = quote, true
+			s.pending = append(s.pending, lineOrg)
+			end, closed := s.skipString(line, begin)
+			if !closed {
+				return
+			}
+			s.quote = ""
+			if isStatementEnd(line[end:]) {
+				s.flush(true)
+				return
+			}
+			s.flush(false)
+			s.scanCode(line[end:])
+			s.endLine()
+			return
+		}
+	}
+
+	onCode(s.clocFile, s.opts, false, line, lineOrg)
+	s.expectDocstring = false
+	if s.depth == 0 && isDefinitionHeader(line) {
+		s.inHeader = true
+	}
+	s.last = 0
+	s.scanCode(line)
+	s.endLine()
+}
+
+// endLine detects the end of a def or class header, after which a docstring may follow.
+func (s *docstringScanner) endLine() {
+	if s.inHeader && s.depth == 0 && s.quote == "" {
+		s.inHeader = false
+		s.expectDocstring = s.last == ':'
+	}
+}
+
+// flush counts the lines of the pending string, as comments if it is a docstring.
+func (s *docstringScanner) flush(isDocstring bool) {
+	for _, lineOrg := range s.pending {
+		line := trimBOM(strings.TrimSpace(lineOrg))
+		switch {
+		case len(line) == 0:
+			onBlank(s.clocFile, s.opts, true, line, lineOrg)
+		case isDocstring && !s.opts.DocstringAsCode:
+			onComment(s.clocFile, s.opts, true, line, lineOrg)
+		default:
+			onCode(s.clocFile, s.opts, false, line, lineOrg)
+		}
+	}
+	s.pending = nil
+	s.docstring = false
+}
+
+// scanCode follows the brackets and the strings of a code line.
+func (s *docstringScanner) scanCode(line string) {
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch c {
+		case '#':
+			return
+		case '"', '\'':
+			s.last = c
+			if quote := strings.Repeat(string(c), 3); strings.HasPrefix(line[i:], quote) {
+				s.quote = quote
+				end, closed := s.skipString(line, i+3)
+				if !closed {
+					return
+				}
+				s.quote = ""
+				i = end - 1
+				continue
+			}
+			for i++; i < len(line) && line[i] != c; i++ {
+				if line[i] == '\\' {
+					i++
+				}
+			}
+		case '(', '[', '{':
+			s.depth++
+			s.last = c
+		case ')', ']', '}':
+			if s.depth > 0 {
+				s.depth--
+			}
+			s.last = c
+		case ' ', '\t':
+		default:
+			s.last = c
+		}
+	}
+}
+
+// skipString returns the position just after the closing quotes of the open string.
+func (s *docstringScanner) skipString(line string, pos int) (end int, closed bool) {
+	for pos < len(line) {
+		if line[pos] == '\\' {
+			pos += 2
+			continue
+		}
+		if strings.HasPrefix(line[pos:], s.quote) {
+			return pos + len(s.quote), true
+		}
+		pos++
+	}
+	return len(line), false
+}
+
+// docstringStart returns the quotes of the triple-quoted string starting the line,
+// with an optional string prefix such as r or f, and the position after them.
+func docstringStart(line string) (quote string, begin int) {
+	i := 0
+	for i < len(line) && i < 2 && strings.IndexByte("rRuUfFbB", line[i]) >= 0 {
+		i++
+	}
+	for _, quote := range []string{`"""`, `'''`} {
+		if strings.HasPrefix(line[i:], quote) {
+			return quote, i + len(quote)
+		}
+	}
+	return "", 0
+}
+
+func isDefinitionHeader(line string) bool {
+	for _, keyword := range []string{"def ", "async def ", "class ", "cdef ", "cpdef "} {
+		if strings.HasPrefix(line, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// isStatementEnd reports whether nothing but a comment follows a string.
+func isStatementEnd(rest string) bool {
+	rest = strings.TrimSpace(rest)
+	return rest == "" || rest[0] == '#'
+}
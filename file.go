@@ -81,7 +81,12 @@ func AnalyzeReader(filename string, language *Language, file io.Reader, opts *Cl
 
 	isFirstLine := true
 	var inComments [][2]string
+	var inLiteral *StringLiteral
 	reader := bufio.NewReader(file)
+	if language.docstrings {
+		analyzeDocstringReader(clocFile, reader, opts)
+		return clocFile
+	}
 
 scannerloop:
 	for {
@@ -110,7 +115,7 @@ scannerloop:
 			continue
 		}
 
-		if len(inComments) == 0 {
+		if len(inComments) == 0 && inLiteral == nil {
 			if isFirstLine {
 				line = trimBOM(line)
 			}
@@ -151,7 +156,8 @@ scannerloop:
 			}
 		}
 
-		if len(inComments) == 0 && !containsComment(line, language.multiLines) {
+		if len(inComments) == 0 && inLiteral == nil &&
+			!containsComment(line, language.multiLines) && !language.containsStringLiteral(line) {
 			onCode(clocFile, opts, len(inComments) > 0, line, lineOrg)
 			continue scannerloop
 		}
@@ -163,11 +169,40 @@ scannerloop:
 		}
 		codeFlags := make([]bool, len(language.multiLines))
 		for pos := 0; pos < lenLine; {
+			// comment markers inside string literals are code
+			if inLiteral != nil {
+				next, closed := inLiteral.skip(line, pos)
+				if closed {
+					inLiteral = nil
+				}
+				for idx := range codeFlags {
+					codeFlags[idx] = true
+				}
+				pos = next
+				continue
+			}
+			if len(inComments) == 0 && len(language.stringLiterals) > 0 && !language.multiLineCommentAt(line[pos:]) {
+				if literal := language.stringLiteralAt(line[pos:]); literal != nil {
+					inLiteral = literal
+					pos += len(literal.Begin)
+					continue
+				}
+				if language.lineCommentAt(line[pos:]) {
+					// the rest of the line is a comment
+					break
+				}
+			}
+
 			for idx, ml := range language.multiLines {
 				begin, end := ml[0], ml[1]
 				lenBegin := len(begin)
 
-				if pos+lenBegin <= lenLine && strings.HasPrefix(line[pos:], begin) && (begin != end || len(inComments) == 0) {
+				// only the comments declared as nested can begin inside a comment of the same kind
+				opens := len(inComments) == 0
+				if n := len(inComments); n > 0 && begin != end && inComments[n-1][0] == begin {
+					opens = language.isNestedComment(begin)
+				}
+				if pos+lenBegin <= lenLine && strings.HasPrefix(line[pos:], begin) && opens {
 					pos += lenBegin
 					inComments = append(inComments, [2]string{begin, end})
 					continue
@@ -186,6 +221,10 @@ scannerloop:
 			pos++
 		}
 
+		if inLiteral != nil && !inLiteral.MultiLine {
+			inLiteral = nil
+		}
+
 		isCode := true
 		for _, b := range codeFlags {
 			if !b {
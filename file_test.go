@@ -2,8 +2,12 @@ package gocloc
 
 import (
 	"bytes"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"sync/atomic"
 	"testing"
 )
 
@@ -501,3 +505,265 @@ js:
 		t.Errorf("invalid logic. lang=%v", clocFile.Lang)
 	}
 }
+
+func TestAnalyzeReaderWithStringLiterals(t *testing.T) {
+	tests := []struct {
+		lang     string
+		source   string
+		code     int32
+		comments int32
+		blanks   int32
+	}{
+		{"Go", "s := \"/*\"\nt := 1\n", 2, 0, 0},
+		{"Go", "s := \"a\\\"/*\"\nt := 1\n", 2, 0, 0},
+		{"Go", "c := '\"' /* comment\n*/\n", 1, 1, 0},
+		{"Go", "c := '\\'' /* a */\nt := 1\n", 2, 0, 0},
+		{"Go", "s := `\n/* not a comment\n\n// nor this\n`\nt := 1\n", 5, 0, 1},
+		{"Go", "u := \"http://x\" /* c\n*/\n", 1, 1, 0},
+		{"Go", "t := 1 // /* not opened\nu := 2\n", 2, 0, 0},
+		{"Go", "/* \"not a string\n*/\nt := 1\n", 1, 2, 0},
+		{"C", "char *s = \"*/ /*\";\nint a;\n", 2, 0, 0},
+		{"C++", "auto s = R\"(\n/* raw\n)\";\nint a;\n", 4, 0, 0},
+		{"C#", "var s = @\"c:\\\n/* x\";\nint a;\n", 3, 0, 0},
+		{"Java", "String s = \"\"\"\n/* text block\n\"\"\";\nint a;\n", 4, 0, 0},
+		{"JavaScript", "let s = `/*\n${a}`;\nlet b = '/*';\n", 3, 0, 0},
+		{"TypeScript", "let s = \"//\" /* c */\n", 1, 0, 0},
+		{"Kotlin", "val s = \"\"\"\n/*\n\"\"\"\n", 3, 0, 0},
+		{"Python", "s = '\"\"\"'\nt = 1\n", 2, 0, 0},
+		{"Python", "s = '''\n\"\"\"\n'''\nt = 1\n", 4, 0, 0},
+		{"Rust", "let s = \"/*\n\";\nlet r = r#\"/*\"#;\n", 3, 0, 0},
+		{"Rust", "fn f<'a>(x: &'a str) {} /* c\n*/\n", 1, 1, 0},
+	}
+
+	languages := NewDefinedLanguages()
+	for _, tt := range tests {
+		language := newLanguageFromDefinition(languages.Langs[tt.lang])
+		clocFile := AnalyzeReader("test", language, bytes.NewBufferString(tt.source), NewClocOptions())
+		if clocFile.Code != tt.code || clocFile.Comments != tt.comments || clocFile.Blanks != tt.blanks {
+			t.Errorf("invalid logic. lang=%v source=%q code=%v comments=%v blanks=%v",
+				tt.lang, tt.source, clocFile.Code, clocFile.Comments, clocFile.Blanks)
+		}
+	}
+}
+
+func TestAnalyzeReaderWithNestedComments(t *testing.T) {
+	tests := []struct {
+		lang     string
+		source   string
+		code     int32
+		comments int32
+	}{
+		{"C", "/* /* */\nint a;\n", 1, 1},
+		{"Java", "/* /* */ int a;\nint b;\n", 2, 0},
+		{"Rust", "/* /* */\nstill a comment\n*/\nlet a = 1;\n", 1, 3},
+		{"Swift", "/* /* /* */ */\n*/\nlet a = 1\n", 1, 2},
+		{"Haskell", "{- {- -}\n-}\nmain = 1\n", 1, 2},
+		{"D", "/+ /+ +/\n+/\nint a;\n", 1, 2},
+		{"D", "/* /* */\nint a;\n", 1, 1},
+		{"D", "/* /+ */\nint a;\n", 1, 1},
+		{"ATS", "(* /* *)\nval a = 1\n", 1, 1},
+	}
+
+	languages := NewDefinedLanguages()
+	for _, tt := range tests {
+		language := newLanguageFromDefinition(languages.Langs[tt.lang])
+		clocFile := AnalyzeReader("test", language, bytes.NewBufferString(tt.source), NewClocOptions())
+		if clocFile.Code != tt.code || clocFile.Comments != tt.comments {
+			t.Errorf("invalid logic. lang=%v source=%q code=%v comments=%v",
+				tt.lang, tt.source, clocFile.Code, clocFile.Comments)
+		}
+	}
+}
+
+func TestAnalyzeReaderWithDocstrings(t *testing.T) {
+	source := `#!/usr/bin/env python
+"""Module docstring.
+
+More text.
+"""
+import os
+
+QUERY = """
+SELECT *
+FROM t
+"""
+
+
+class A:
+    r'''Class docstring.'''
+
+    def f(self,
+          a):
+        f"""Function docstring."""
+        return a
+
+    async def g(self) -> str:
+        # comment
+        '''Docstring after a comment
+        '''
+        x = """not a docstring"""
+        """expression after code"""
+        return x
+
+def h(): return """inline"""
+
+def k():
+    """doc""".strip()
+    pass
+`
+
+	tests := []struct {
+		asCode   bool
+		code     int32
+		comments int32
+		blanks   int32
+	}{
+		{false, 18, 8, 8},
+		{true, 25, 1, 8},
+	}
+
+	language := newLanguageFromDefinition(NewDefinedLanguages().Langs["Python"])
+	for _, tt := range tests {
+		clocOpts := NewClocOptions()
+		clocOpts.DocstringAsCode = tt.asCode
+		var comments []string
+		clocOpts.OnComment = func(line string) {
+			comments = append(comments, line)
+		}
+		clocFile := AnalyzeReader("test.py", language, bytes.NewBufferString(source), clocOpts)
+		if clocFile.Code != tt.code || clocFile.Comments != tt.comments || clocFile.Blanks != tt.blanks {
+			t.Errorf("invalid logic. asCode=%v code=%v comments=%v blanks=%v comments=%q",
+				tt.asCode, clocFile.Code, clocFile.Comments, clocFile.Blanks, comments)
+		}
+	}
+}
+
+// countingFileSystem counts the source files opened and the bytes read through it.
+// The ignore files are not counted.
+type countingFileSystem struct {
+	fileSystem
+	opens int64
+	bytes int64
+}
+
+type countingReader struct {
+	io.ReadCloser
+	fsys *countingFileSystem
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	atomic.AddInt64(&r.fsys.bytes, int64(n))
+	return n, err
+}
+
+func (c *countingFileSystem) Open(name string) (io.ReadCloser, error) {
+	f, err := c.fileSystem.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(ignoreFileNames, filepath.Base(name)) {
+		return f, nil
+	}
+	atomic.AddInt64(&c.opens, 1)
+	return &countingReader{ReadCloser: f, fsys: c}, nil
+}
+
+func writeBenchmarkTree(tb testing.TB, n int) (root string, size int64) {
+	tb.Helper()
+	root = tb.TempDir()
+	sources := map[string]string{
+		"ts":   "// comment\nconst a: number = %d;\n\n/* block\n comment */\nexport default a;\n",
+		"m":    "#import <Foundation/Foundation.h>\n// comment %d\n@interface A : NSObject\n@end\n",
+		"go":   "package main\n\n// comment\nvar a = %d\n",
+		"py":   "#!/usr/bin/env python\n# comment\na = %d\n",
+		"sh":   "#!/bin/sh\necho %d\n",
+		"data": "\x00\x01binary %d\n",
+	}
+	for i := 0; i < n; i++ {
+		for ext, src := range sources {
+			content := fmt.Sprintf(src, i)
+			name := filepath.Join(root, fmt.Sprintf("file%d.%s", i, ext))
+			if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+				tb.Fatalf("os.WriteFile() error. err=[%v]", err)
+			}
+			size += int64(len(content))
+		}
+	}
+	return root, size
+}
+
+func TestAnalyzeReadsEachFileOnce(t *testing.T) {
+	root, size := writeBenchmarkTree(t, 10)
+
+	fsys := &countingFileSystem{fileSystem: osFileSystem{}}
+	processor := NewProcessor(NewDefinedLanguages(), NewClocOptions())
+	processor.fsys = fsys
+	result, err := processor.Analyze([]string{root})
+	if err != nil {
+		t.Fatalf("Analyze() error. err=[%v]", err)
+	}
+
+	if fsys.opens != 60 {
+		t.Errorf("invalid logic. opens=%v", fsys.opens)
+	}
+	if fsys.bytes != size {
+		t.Errorf("invalid logic. bytes=%v, size=%v", fsys.bytes, size)
+	}
+	if result.Total.Total != 50 {
+		t.Errorf("invalid logic. files=%v", result.Total.Total)
+	}
+	for _, lang := range []string{"TypeScript", "Objective-C", "Go", "Python", "Bourne Shell"} {
+		if len(result.Languages[lang].Files) != 10 {
+			t.Errorf("invalid logic. lang=%v files=%v", lang, len(result.Languages[lang].Files))
+		}
+	}
+}
+
+func benchmarkAnalyze(b *testing.B, workers int) {
+	root, size := writeBenchmarkTree(b, 100)
+	opts := NewClocOptions()
+	opts.Workers = workers
+	fsys := &countingFileSystem{fileSystem: osFileSystem{}}
+	processor := NewProcessor(NewDefinedLanguages(), opts)
+	processor.fsys = fsys
+
+	b.SetBytes(size)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := processor.Analyze([]string{root}); err != nil {
+			b.Fatalf("Analyze() error. err=[%v]", err)
+		}
+	}
+	b.StopTimer()
+
+	files := float64(600 * b.N)
+	b.ReportMetric(float64(fsys.opens)/files, "opens/file")
+	b.ReportMetric(float64(fsys.bytes)/float64(size*int64(b.N)), "reads/byte")
+}
+
+func BenchmarkAnalyze(b *testing.B) {
+	benchmarkAnalyze(b, 1)
+}
+
+func BenchmarkAnalyzeParallel(b *testing.B) {
+	benchmarkAnalyze(b, 0)
+}
+
+func BenchmarkReadSource(b *testing.B) {
+	root, _ := writeBenchmarkTree(b, 1)
+	path := filepath.Join(root, "file0.ts")
+	langs := NewDefinedLanguages()
+	opts := NewClocOptions()
+	fsys := &countingFileSystem{fileSystem: osFileSystem{}}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, ok := readSource(fsys, path, langs, opts); !ok {
+			b.Fatalf("readSource() error. path=%v", path)
+		}
+	}
+	b.StopTimer()
+
+	b.ReportMetric(float64(fsys.opens)/float64(b.N), "opens/op")
+}
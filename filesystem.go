@@ -0,0 +1,94 @@
+package gocloc
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// fileSystem abstracts the access to the files to be analyzed.
+type fileSystem interface {
+	// Walk walks the file tree rooted at root in lexical order.
+	Walk(root string, fn filepath.WalkFunc) error
+	// Open opens the named file for reading.
+	Open(name string) (io.ReadCloser, error)
+	// Join joins path elements with the separator of the file system.
+	Join(elem ...string) string
+	// IgnoreRoot returns the directory top whose ignore files apply to root,
+	// the slash separated path of root relative to top, and the exclude file
+	// of the repository containing root, if any.
+	IgnoreRoot(root string) (top, prefix, exclude string)
+}
+
+// osFileSystem is the fileSystem of the operating system.
+type osFileSystem struct{}
+
+func (osFileSystem) Walk(root string, fn filepath.WalkFunc) error {
+	return filepath.Walk(root, fn)
+}
+
+func (osFileSystem) Open(name string) (io.ReadCloser, error) {
+	return os.Open(name)
+}
+
+func (osFileSystem) Join(elem ...string) string {
+	return filepath.Join(elem...)
+}
+
+// IgnoreRoot returns the enclosing git repository when there is one, otherwise root.
+func (osFileSystem) IgnoreRoot(root string) (top, prefix, exclude string) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		absRoot = root
+	}
+	if info, err := os.Stat(absRoot); err == nil && !info.IsDir() {
+		absRoot = filepath.Dir(absRoot)
+	}
+
+	top = absRoot
+	if gitRoot, gitDir, ok := findGitRepository(absRoot); ok {
+		top = gitRoot
+		exclude = filepath.Join(gitDir, "info", "exclude")
+	}
+	if rel, err := filepath.Rel(top, absRoot); err == nil && rel != "." {
+		prefix = filepath.ToSlash(rel)
+	}
+	return top, prefix, exclude
+}
+
+// ioFileSystem is the fileSystem of an io/fs implementation.
+// Its paths are slash separated and unrooted, as required by io/fs.
+type ioFileSystem struct {
+	fsys fs.FS
+}
+
+func (f ioFileSystem) Walk(root string, fn filepath.WalkFunc) error {
+	return fs.WalkDir(f.fsys, root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fn(name, nil, err)
+		}
+		info, err := d.Info()
+		if err != nil {
+			return fn(name, nil, err)
+		}
+		return fn(name, info, nil)
+	})
+}
+
+func (f ioFileSystem) Open(name string) (io.ReadCloser, error) {
+	return f.fsys.Open(name)
+}
+
+func (ioFileSystem) Join(elem ...string) string {
+	return path.Join(elem...)
+}
+
+// IgnoreRoot returns root itself, there is no enclosing repository in an io/fs.
+func (f ioFileSystem) IgnoreRoot(root string) (top, prefix, exclude string) {
+	if info, err := fs.Stat(f.fsys, root); err == nil && !info.IsDir() {
+		root = path.Dir(root)
+	}
+	return root, "", ""
+}
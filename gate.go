@@ -0,0 +1,155 @@
+package gocloc
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GateLimits are the thresholds of a quality gate. A zero limit is not checked.
+type GateLimits struct {
+	// MaxCode is the maximum number of code lines.
+	MaxCode int32 `yaml:"max_code" json:"max_code"`
+	// MinCommentRatio is the minimum percentage of comment lines in the comment and code lines.
+	MinCommentRatio float64 `yaml:"min_comment_ratio" json:"min_comment_ratio"`
+	// MaxFileCode is the maximum number of code lines of each file.
+	MaxFileCode int32 `yaml:"max_file_code" json:"max_file_code"`
+	// MaxCodeGrowth is the maximum number of code lines added since a baseline, checked by CheckBaseline.
+	MaxCodeGrowth int32 `yaml:"max_code_growth" json:"max_code_growth"`
+}
+
+// Gate checks the limits on the total and on each language of a result.
+type Gate struct {
+	GateLimits `yaml:",inline"`
+	// Languages are the limits of each language, checked in addition to the limits of the total.
+	// The MaxFileCode of a language replaces the one of the total for the files of the language.
+	Languages map[string]GateLimits `yaml:"languages" json:"languages"`
+}
+
+// GateViolation is a limit of a gate exceeded by the total, a language or a file.
+type GateViolation struct {
+	// Name is "TOTAL", the name of the language or the name of the file.
+	Name  string
+	Rule  string
+	Value float64
+	Limit float64
+}
+
+func (v GateViolation) String() string {
+	switch v.Rule {
+	case "min_comment_ratio":
+		return fmt.Sprintf("%s: comment ratio %.2f%% is below %.2f%%", v.Name, v.Value, v.Limit)
+	case "max_file_code":
+		return fmt.Sprintf("%s: %.0f code lines exceed %.0f per file", v.Name, v.Value, v.Limit)
+	case "max_code_growth":
+		return fmt.Sprintf("%s: %.0f code lines added exceed the budget of %.0f", v.Name, v.Value, v.Limit)
+	}
+	return fmt.Sprintf("%s: %.0f code lines exceed %.0f", v.Name, v.Value, v.Limit)
+}
+
+// ReadGate reads a gate from YAML or JSON, such as
+//
+//	max_code: 100000
+//	min_comment_ratio: 10
+//	max_file_code: 2000
+//	languages:
+//	  Go:
+//	    max_file_code: 1000
+func ReadGate(r io.Reader) (*Gate, error) {
+	gate := &Gate{}
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(gate); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("gate: %v", err)
+	}
+	return gate, nil
+}
+
+// Check returns the violations of the gate by the result, the total first,
+// then the languages and the files sorted by name.
+// The limits of the files are checked only if the result has the files.
+func (g *Gate) Check(result *Result) []GateViolation {
+	var violations []GateViolation
+	violations = append(violations, checkGateLimits("TOTAL", g.GateLimits, result.Total)...)
+
+	names := make([]string, 0, len(g.Languages))
+	for name := range g.Languages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if language, ok := result.Languages[name]; ok {
+			violations = append(violations, checkGateLimits(name, g.Languages[name], language)...)
+		}
+	}
+
+	files := make([]string, 0, len(result.Files))
+	for name := range result.Files {
+		files = append(files, name)
+	}
+	sort.Strings(files)
+	for _, name := range files {
+		file := result.Files[name]
+		limit := g.MaxFileCode
+		if l, ok := g.Languages[file.Lang]; ok && l.MaxFileCode > 0 {
+			limit = l.MaxFileCode
+		}
+		if limit > 0 && file.Code > limit {
+			violations = append(violations, GateViolation{
+				Name:  name,
+				Rule:  "max_file_code",
+				Value: float64(file.Code),
+				Limit: float64(limit),
+			})
+		}
+	}
+	return violations
+}
+
+// CheckBaseline returns the violations of the growth budgets of the gate by the comparison
+// with a baseline, the total first, then the languages sorted by name.
+func (g *Gate) CheckBaseline(baseline *BaselineResult) []GateViolation {
+	var violations []GateViolation
+	check := func(limit int32, delta BaselineDelta) {
+		if limit > 0 && delta.Code > limit {
+			violations = append(violations, GateViolation{
+				Name:  delta.Name,
+				Rule:  "max_code_growth",
+				Value: float64(delta.Code),
+				Limit: float64(limit),
+			})
+		}
+	}
+	check(g.MaxCodeGrowth, baseline.Total)
+	for _, delta := range baseline.Languages {
+		if limits, ok := g.Languages[delta.Name]; ok {
+			check(limits.MaxCodeGrowth, delta)
+		}
+	}
+	return violations
+}
+
+func checkGateLimits(name string, limits GateLimits, language *Language) []GateViolation {
+	var violations []GateViolation
+	if limits.MaxCode > 0 && language.Code > limits.MaxCode {
+		violations = append(violations, GateViolation{
+			Name:  name,
+			Rule:  "max_code",
+			Value: float64(language.Code),
+			Limit: float64(limits.MaxCode),
+		})
+	}
+	// the comment ratio of no comment and code lines is not checked
+	ratio := commentRatio(language.Comments, language.Code)
+	if limits.MinCommentRatio > 0 && language.Comments+language.Code > 0 && ratio < limits.MinCommentRatio {
+		violations = append(violations, GateViolation{
+			Name:  name,
+			Rule:  "min_comment_ratio",
+			Value: ratio,
+			Limit: limits.MinCommentRatio,
+		})
+	}
+	return violations
+}
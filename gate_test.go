@@ -0,0 +1,103 @@
+package gocloc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGateCheck(t *testing.T) {
+	gate, err := ReadGate(strings.NewReader(`
+max_code: 25
+min_comment_ratio: 20
+max_file_code: 15
+languages:
+  Go:
+    max_file_code: 30
+    min_comment_ratio: 10
+  Python:
+    max_code: 5
+  Rust:
+    max_code: 1
+`))
+	if err != nil {
+		t.Fatalf("ReadGate() error. err=[%v]", err)
+	}
+
+	var violations []string
+	for _, v := range gate.Check(newTestRenderResult()) {
+		violations = append(violations, v.String())
+	}
+	expected := []string{
+		"TOTAL: 30 code lines exceed 25",
+		"TOTAL: comment ratio 16.67% is below 20.00%",
+		"Python: 10 code lines exceed 5",
+	}
+	if strings.Join(violations, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("invalid result. '%s'", strings.Join(violations, "\n"))
+	}
+
+	gate = &Gate{GateLimits: GateLimits{MaxFileCode: 15}}
+	violations = nil
+	for _, v := range gate.Check(newTestRenderResult()) {
+		violations = append(violations, v.String())
+	}
+	if len(violations) != 1 || violations[0] != "main.go: 20 code lines exceed 15 per file" {
+		t.Errorf("invalid result. '%s'", strings.Join(violations, "\n"))
+	}
+
+	if v := (&Gate{}).Check(newTestRenderResult()); len(v) != 0 {
+		t.Errorf("invalid logic. violations=%v", v)
+	}
+
+	// a result without comment and code lines has no comment ratio to check
+	empty := &Result{
+		Total:     &Language{},
+		Files:     map[string]*ClocFile{},
+		Languages: map[string]*Language{"Go": {Name: "Go"}},
+	}
+	gate = &Gate{
+		GateLimits: GateLimits{MinCommentRatio: 1},
+		Languages:  map[string]GateLimits{"Go": {MinCommentRatio: 1}},
+	}
+	if v := gate.Check(empty); len(v) != 0 {
+		t.Errorf("invalid logic. violations=%v", v)
+	}
+}
+
+func TestReadGate(t *testing.T) {
+	gate, err := ReadGate(strings.NewReader(`{"max_code": 10, "languages": {"Go": {"min_comment_ratio": 5.5}}}`))
+	if err != nil {
+		t.Fatalf("ReadGate() error. err=[%v]", err)
+	}
+	if gate.MaxCode != 10 || gate.Languages["Go"].MinCommentRatio != 5.5 {
+		t.Errorf("invalid logic. gate=%+v", gate)
+	}
+
+	if _, err := ReadGate(strings.NewReader("max_lines: 10")); err == nil {
+		t.Errorf("invalid logic. unknown field should be an error")
+	}
+	if gate, err := ReadGate(strings.NewReader("")); err != nil || gate.MaxCode != 0 {
+		t.Errorf("invalid logic. gate=%+v err=%v", gate, err)
+	}
+}
+
+func TestGateCheckBaseline(t *testing.T) {
+	gate := &Gate{
+		GateLimits: GateLimits{MaxCodeGrowth: 1},
+		Languages: map[string]GateLimits{
+			"Go":   {MaxCodeGrowth: 5},
+			"Rust": {MaxCodeGrowth: 5},
+		},
+	}
+	var violations []string
+	for _, v := range gate.CheckBaseline(CompareResults(newTestBaselineResults())) {
+		violations = append(violations, v.String())
+	}
+	expected := []string{
+		"TOTAL: 2 code lines added exceed the budget of 1",
+		"Rust: 7 code lines added exceed the budget of 5",
+	}
+	if strings.Join(violations, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("invalid result. '%s'", strings.Join(violations, "\n"))
+	}
+}
@@ -0,0 +1,407 @@
+package gocloc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AnalyzeGitRef executes gocloc parsing for the paths argument as they are
+// in the git revision ref (a commit, tag or branch name) of the local repository,
+// without checking it out. The repository is the one containing the first path,
+// and all paths must belong to it.
+func (p *Processor) AnalyzeGitRef(ref string, paths []string) (*Result, error) {
+	if len(paths) == 0 {
+		return p.Analyze(paths)
+	}
+
+	fsys, err := newGitFileSystem(existingDir(paths[0]), ref)
+	if err != nil {
+		return nil, err
+	}
+	defer fsys.Close()
+
+	gp := *p
+	gp.fsys = fsys
+	return gp.Analyze(paths)
+}
+
+// AnalyzeChangedSince executes gocloc parsing for the files of the paths argument
+// added or modified in the work tree since the merge base of the git revision ref
+// and HEAD, that is the changes of a branch based on ref. It returns the result of
+// these files and the result of the same files in the merge base, which lacks the
+// added files. With untracked, the untracked files which are not ignored are counted
+// as added. The repository is the one containing the first path.
+func (p *Processor) AnalyzeChangedSince(ref string, paths []string, untracked bool) (current, base *Result, err error) {
+	if len(paths) == 0 {
+		return nil, nil, fmt.Errorf("no paths to analyze")
+	}
+	top, err := gitTopLevel(existingDir(paths[0]))
+	if err != nil {
+		return nil, nil, err
+	}
+	out, err := runGit(top, "merge-base", ref, "HEAD")
+	if err != nil {
+		return nil, nil, err
+	}
+	mergeBase := strings.TrimSpace(string(out))
+
+	out, err = runGit(top, "diff", "--name-only", "-z", "--no-renames", "--diff-filter=AM", mergeBase, "--")
+	if err != nil {
+		return nil, nil, err
+	}
+	names := bytes.Split(out, []byte{0})
+	if untracked {
+		out, err = runGit(top, "ls-files", "-z", "--others", "--exclude-standard", "--full-name")
+		if err != nil {
+			return nil, nil, err
+		}
+		names = append(names, bytes.Split(out, []byte{0})...)
+	}
+	changed := &changedFileSystem{
+		files: make(map[string]struct{}),
+		dirs:  make(map[string]struct{}),
+	}
+	for _, name := range names {
+		if len(name) != 0 {
+			changed.add(filepath.Join(top, filepath.FromSlash(string(name))))
+		}
+	}
+
+	cp := *p
+	changed.fileSystem = p.fsys
+	cp.fsys = changed
+	if current, err = cp.Analyze(paths); err != nil {
+		return nil, nil, err
+	}
+
+	fsys, err := newGitFileSystem(top, mergeBase)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer fsys.Close()
+	changed.fileSystem = fsys
+	if base, err = cp.Analyze(paths); err != nil {
+		return nil, nil, err
+	}
+	return current, base, nil
+}
+
+// changedFileSystem restricts the walk of a fileSystem to the changed files,
+// without entering the directories which contain none of them.
+type changedFileSystem struct {
+	fileSystem
+	// files are the absolute paths of the changed files, and dirs are their parent directories.
+	files map[string]struct{}
+	dirs  map[string]struct{}
+}
+
+func (c *changedFileSystem) add(file string) {
+	c.files[file] = struct{}{}
+	for dir := filepath.Dir(file); ; dir = filepath.Dir(dir) {
+		if _, ok := c.dirs[dir]; ok {
+			return
+		}
+		c.dirs[dir] = struct{}{}
+		if filepath.Dir(dir) == dir {
+			return
+		}
+	}
+}
+
+func (c *changedFileSystem) Walk(root string, fn filepath.WalkFunc) error {
+	return c.fileSystem.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return fn(path, info, err)
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return fn(path, info, err)
+		}
+		if info.IsDir() {
+			if _, ok := c.dirs[abs]; !ok {
+				return filepath.SkipDir
+			}
+		} else if _, ok := c.files[abs]; !ok {
+			return nil
+		}
+		return fn(path, info, nil)
+	})
+}
+
+// existingDir returns the nearest existing directory of the path,
+// because the path may only exist in the revision.
+func existingDir(name string) string {
+	dir := name
+	for {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		dir = parent
+	}
+}
+
+// gitFileSystem is the fileSystem of the tree of a git revision.
+// Paths are the paths of the work tree, and file contents are read
+// from the object database with a single `git cat-file --batch` process.
+type gitFileSystem struct {
+	top   string
+	blobs map[string]*gitFileInfo
+	dirs  map[string][]*gitFileInfo
+
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+}
+
+type gitFileInfo struct {
+	name   string
+	object string
+	size   int64
+	mode   os.FileMode
+}
+
+func (fi *gitFileInfo) Name() string       { return fi.name }
+func (fi *gitFileInfo) Size() int64        { return fi.size }
+func (fi *gitFileInfo) Mode() os.FileMode  { return fi.mode }
+func (fi *gitFileInfo) ModTime() time.Time { return time.Time{} }
+func (fi *gitFileInfo) IsDir() bool        { return fi.mode.IsDir() }
+func (fi *gitFileInfo) Sys() interface{}   { return nil }
+
+func runGit(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("git %s: %v", args[0], err)
+	}
+	return out, nil
+}
+
+// gitTopLevel returns the absolute path of the top of the work tree containing dir.
+func gitTopLevel(dir string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	out, err := runGit(absDir, "rev-parse", "--show-prefix")
+	if err != nil {
+		return "", err
+	}
+	// derive the top from dir instead of --show-toplevel, which resolves symbolic links
+	top := absDir
+	if prefix := strings.Trim(strings.TrimSpace(string(out)), "/"); prefix != "" {
+		for range strings.Split(prefix, "/") {
+			top = filepath.Dir(top)
+		}
+	}
+	return top, nil
+}
+
+// newGitFileSystem lists the tree of ref in the repository containing dir.
+// Symbolic links and submodules are not part of the file system.
+func newGitFileSystem(dir, ref string) (*gitFileSystem, error) {
+	top, err := gitTopLevel(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := runGit(top, "ls-tree", "-r", "-l", "-z", "--full-tree", ref)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &gitFileSystem{
+		top:   top,
+		blobs: make(map[string]*gitFileInfo),
+		dirs:  map[string][]*gitFileInfo{"": nil},
+	}
+	for _, entry := range bytes.Split(out, []byte{0}) {
+		// <mode> SP <type> SP <object> SP+ <size> TAB <path>
+		tab := bytes.IndexByte(entry, '\t')
+		if tab < 0 {
+			continue
+		}
+		fields := strings.Fields(string(entry[:tab]))
+		if len(fields) != 4 || fields[1] != "blob" || fields[0] == "120000" {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			continue
+		}
+		name := string(entry[tab+1:])
+		fi := &gitFileInfo{name: path.Base(name), object: fields[2], size: size, mode: 0o644}
+		if fields[0] == "100755" {
+			fi.mode = 0o755
+		}
+		g.blobs[name] = fi
+		g.addEntry(path.Dir(name), fi)
+	}
+	for _, entries := range g.dirs {
+		sort.Slice(entries, func(i, j int) bool { return entries[i].name < entries[j].name })
+	}
+
+	g.cmd = exec.Command("git", "-C", top, "cat-file", "--batch")
+	if g.stdin, err = g.cmd.StdinPipe(); err != nil {
+		return nil, err
+	}
+	stdout, err := g.cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	g.stdout = bufio.NewReader(stdout)
+	if err := g.cmd.Start(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// addEntry registers fi in the directory dir and the missing parent directories.
+func (g *gitFileSystem) addEntry(dir string, fi *gitFileInfo) {
+	if dir == "." {
+		dir = ""
+	}
+	_, exists := g.dirs[dir]
+	g.dirs[dir] = append(g.dirs[dir], fi)
+	if !exists {
+		g.addEntry(path.Dir(dir), &gitFileInfo{name: path.Base(dir), mode: os.ModeDir | 0o755})
+	}
+}
+
+// Close stops the git process reading the objects.
+func (g *gitFileSystem) Close() error {
+	g.stdin.Close()
+	return g.cmd.Wait()
+}
+
+// rel converts a work tree path to the slash separated path in the tree.
+func (g *gitFileSystem) rel(name string) (string, bool) {
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(g.top, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == "." {
+		rel = ""
+	}
+	return rel, true
+}
+
+func (g *gitFileSystem) stat(name string) (string, *gitFileInfo, bool) {
+	rel, ok := g.rel(name)
+	if !ok {
+		return "", nil, false
+	}
+	if fi, ok := g.blobs[rel]; ok {
+		return rel, fi, true
+	}
+	if _, ok := g.dirs[rel]; ok {
+		return rel, &gitFileInfo{name: path.Base(rel), mode: os.ModeDir | 0o755}, true
+	}
+	return "", nil, false
+}
+
+// Walk walks the tree with the semantics of filepath.Walk.
+func (g *gitFileSystem) Walk(root string, fn filepath.WalkFunc) error {
+	rel, info, ok := g.stat(root)
+	var err error
+	if !ok {
+		err = fn(root, nil, &os.PathError{Op: "lstat", Path: root, Err: os.ErrNotExist})
+	} else {
+		err = g.walk(root, rel, info, fn)
+	}
+	if err == filepath.SkipDir || err == filepath.SkipAll {
+		return nil
+	}
+	return err
+}
+
+func (g *gitFileSystem) walk(name, rel string, info *gitFileInfo, fn filepath.WalkFunc) error {
+	if !info.IsDir() {
+		return fn(name, info, nil)
+	}
+	if err := fn(name, info, nil); err != nil {
+		return err
+	}
+	for _, child := range g.dirs[rel] {
+		err := g.walk(filepath.Join(name, child.name), path.Join(rel, child.name), child, fn)
+		if err != nil && (!child.IsDir() || err != filepath.SkipDir) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Open reads the whole blob of the named file.
+func (g *gitFileSystem) Open(name string) (io.ReadCloser, error) {
+	rel, ok := g.rel(name)
+	fi := g.blobs[rel]
+	if !ok || fi == nil {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, err := fmt.Fprintln(g.stdin, fi.object); err != nil {
+		return nil, err
+	}
+	// <object> SP <type> SP <size> LF <contents> LF
+	header, err := g.stdout.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(header)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("git cat-file: %s", strings.TrimSpace(header))
+	}
+	size, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	content := make([]byte, size+1)
+	if _, err := io.ReadFull(g.stdout, content); err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(content[:size])), nil
+}
+
+func (g *gitFileSystem) Join(elem ...string) string {
+	return filepath.Join(elem...)
+}
+
+// IgnoreRoot returns the top of the work tree. The ignore files are the
+// ones of the revision, and the local exclude file does not apply.
+func (g *gitFileSystem) IgnoreRoot(root string) (top, prefix, exclude string) {
+	rel, info, ok := g.stat(root)
+	if ok && !info.IsDir() {
+		rel = path.Dir(rel)
+	}
+	if rel == "." {
+		rel = ""
+	}
+	return g.top, rel, ""
+}
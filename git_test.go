@@ -0,0 +1,215 @@
+package gocloc
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func gitCommand(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	args = append([]string{"-C", dir, "-c", "user.name=gocloc", "-c", "user.email=gocloc@example.com"}, args...)
+	if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		t.Fatalf("git %v error. err=[%v] output=[%s]", args, err, out)
+	}
+}
+
+func TestAnalyzeGitRef(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	root := writeTestTree(t, map[string]string{
+		".gitignore":      "*.gen.go\n",
+		"main.go":         "package main\n\n// comment\nfunc main() {}\n",
+		"sub/app.py":      "#!/usr/bin/env python\n# comment\na = 1\n",
+		"sub/script":      "#!/bin/sh\necho 1\n",
+		"sub/lib.js":      "var a = 1;\n",
+		"docs/README.txt": "text\n",
+	})
+	gitCommand(t, root, "init", "-q")
+	gitCommand(t, root, "add", "-A")
+	gitCommand(t, root, "commit", "-q", "-m", "first")
+	gitCommand(t, root, "tag", "v1")
+
+	// the working tree differs from the revision
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() error. err=[%v]", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "new.go"), []byte("package main\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() error. err=[%v]", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "ignored.gen.go"), []byte("package main\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() error. err=[%v]", err)
+	}
+	gitCommand(t, root, "rm", "-q", "sub/lib.js")
+	gitCommand(t, root, "commit", "-q", "-m", "second")
+
+	opts := NewClocOptions()
+	opts.ExcludeExts["Python"] = struct{}{}
+	processor := NewProcessor(NewDefinedLanguages(), opts)
+	result, err := processor.AnalyzeGitRef("v1", []string{root})
+	if err != nil {
+		t.Fatalf("AnalyzeGitRef() error. err=[%v]", err)
+	}
+
+	var found []string
+	for file := range result.Files {
+		rel, _ := filepath.Rel(root, file)
+		found = append(found, filepath.ToSlash(rel))
+	}
+	sort.Strings(found)
+	expected := []string{"docs/README.txt", "main.go", "sub/lib.js", "sub/script"}
+	if !equalStrings(found, expected) {
+		t.Errorf("invalid logic. files=%v", found)
+	}
+	if goLang := result.Languages["Go"]; goLang == nil || goLang.Code != 2 || goLang.Comments != 1 || goLang.Blanks != 1 {
+		t.Errorf("invalid logic. Go=%+v", goLang)
+	}
+	if result.Languages["Bourne Shell"] == nil {
+		t.Errorf("invalid logic. shebang is not detected")
+	}
+
+	// a sub directory of the repository
+	result, err = processor.AnalyzeGitRef("v1", []string{filepath.Join(root, "sub")})
+	if err != nil {
+		t.Fatalf("AnalyzeGitRef() error. err=[%v]", err)
+	}
+	if result.Total.Total != 2 {
+		t.Errorf("invalid logic. total=%v", result.Total.Total)
+	}
+
+	if _, err := processor.AnalyzeGitRef("no-such-ref", []string{root}); err == nil {
+		t.Errorf("invalid logic. unknown ref should be an error")
+	}
+}
+
+func TestAnalyzeChangedSince(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	root := writeTestTree(t, map[string]string{
+		"main.go":    "package main\n\n// comment\nfunc main() {}\n",
+		"sub/app.py": "# comment\na = 1\n",
+	})
+	gitCommand(t, root, "init", "-q")
+	gitCommand(t, root, "add", "-A")
+	gitCommand(t, root, "commit", "-q", "-m", "first")
+	gitCommand(t, root, "branch", "base")
+
+	writeFile := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("os.WriteFile() error. err=[%v]", err)
+		}
+	}
+	writeFile("main.go", "package main\n\n// comment\nfunc main() {}\n\nfunc f() {}\n")
+	writeFile("new.go", "package main\n")
+	gitCommand(t, root, "add", "-A")
+	gitCommand(t, root, "commit", "-q", "-m", "feature")
+
+	// the changes of the base branch after the merge base are not counted
+	gitCommand(t, root, "checkout", "-q", "base")
+	writeFile("sub/app.py", "a = 2\n")
+	gitCommand(t, root, "commit", "-q", "-a", "-m", "base")
+	gitCommand(t, root, "checkout", "-q", "-")
+
+	writeFile("untracked.go", "package main\n\nvar a = 1\n")
+
+	processor := NewProcessor(NewDefinedLanguages(), NewClocOptions())
+	for _, untracked := range []bool{false, true} {
+		current, base, err := processor.AnalyzeChangedSince("base", []string{root}, untracked)
+		if err != nil {
+			t.Fatalf("AnalyzeChangedSince() error. err=[%v]", err)
+		}
+
+		var found []string
+		for file := range current.Files {
+			rel, _ := filepath.Rel(root, file)
+			found = append(found, filepath.ToSlash(rel))
+		}
+		sort.Strings(found)
+		expected := "[main.go new.go]"
+		if untracked {
+			expected = "[main.go new.go untracked.go]"
+		}
+		if fmt.Sprint(found) != expected {
+			t.Errorf("invalid logic. untracked=%v files=%v", untracked, found)
+		}
+		if len(base.Files) != 1 || base.Files[filepath.Join(root, "main.go")].Code != 2 {
+			t.Errorf("invalid logic. base files=%v", base.Files)
+		}
+
+		delta := CompareResults(base, current).Total
+		expectedDelta := BaselineDelta{Name: "TOTAL", Status: DiffModified, Files: 1, Code: 2, Blanks: 1}
+		if untracked {
+			expectedDelta = BaselineDelta{Name: "TOTAL", Status: DiffModified, Files: 2, Code: 4, Blanks: 2}
+		}
+		if delta != expectedDelta {
+			t.Errorf("invalid logic. untracked=%v delta=%+v", untracked, delta)
+		}
+	}
+
+	if _, _, err := processor.AnalyzeChangedSince("no-such-ref", []string{root}, false); err == nil {
+		t.Errorf("invalid logic. unknown ref should be an error")
+	}
+}
+
+// recordingFileSystem records the paths walked by the underlying file system.
+type recordingFileSystem struct {
+	osFileSystem
+	walked []string
+}
+
+func (r *recordingFileSystem) Walk(root string, fn filepath.WalkFunc) error {
+	return r.osFileSystem.Walk(root, func(path string, info os.FileInfo, err error) error {
+		r.walked = append(r.walked, path)
+		return fn(path, info, err)
+	})
+}
+
+func TestChangedFileSystemWalk(t *testing.T) {
+	root := writeTestTree(t, map[string]string{
+		"main.go":           "package main\n",
+		"other.go":          "package main\n",
+		"sub/changed.go":    "package sub\n",
+		"sub/same.go":       "package sub\n",
+		"vendor/a/b/lib.go": "package b\n",
+	})
+	recording := &recordingFileSystem{}
+	changed := &changedFileSystem{
+		fileSystem: recording,
+		files:      make(map[string]struct{}),
+		dirs:       make(map[string]struct{}),
+	}
+	changed.add(filepath.Join(root, "main.go"))
+	changed.add(filepath.Join(root, "sub", "changed.go"))
+
+	var visited []string
+	err := changed.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			rel, _ := filepath.Rel(root, path)
+			visited = append(visited, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() error. err=[%v]", err)
+	}
+	if fmt.Sprint(visited) != "[main.go sub/changed.go]" {
+		t.Errorf("invalid logic. visited=%v", visited)
+	}
+	for _, path := range recording.walked {
+		if rel, _ := filepath.Rel(root, path); strings.HasPrefix(filepath.ToSlash(rel), "vendor/") {
+			t.Errorf("invalid logic. %s is walked in a directory without changed files", rel)
+		}
+	}
+}
@@ -1,9 +1,19 @@
 package gocloc
 
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"runtime"
+	"sync"
+	"time"
+)
+
 // Processor is gocloc analyzing processor.
 type Processor struct {
 	langs *DefinedLanguages
 	opts  *ClocOptions
+	fsys  fileSystem
 }
 
 // Result defined processing result.
@@ -12,6 +22,25 @@ type Result struct {
 	Files         map[string]*ClocFile
 	Languages     map[string]*Language
 	MaxPathLength int
+	// Elapsed is the time spent on the analysis.
+	Elapsed time.Duration
+}
+
+// FilesPerSecond returns the number of files analyzed per second.
+func (r *Result) FilesPerSecond() float64 {
+	return perSecond(r.Total.Total, r.Elapsed)
+}
+
+// LinesPerSecond returns the number of lines analyzed per second.
+func (r *Result) LinesPerSecond() float64 {
+	return perSecond(r.Total.Blanks+r.Total.Comments+r.Total.Code, r.Elapsed)
+}
+
+func perSecond(n int32, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(n) / elapsed.Seconds()
 }
 
 // NewProcessor returns Processor.
@@ -19,46 +48,169 @@ func NewProcessor(langs *DefinedLanguages, options *ClocOptions) *Processor {
 	return &Processor{
 		langs: langs,
 		opts:  options,
+		fsys:  osFileSystem{},
 	}
 }
 
+type lineKind int8
+
+const (
+	lineCode lineKind = iota
+	lineComment
+	lineBlank
+)
+
+// lineEvent is a recorded callback invocation, replayed by the aggregator.
+type lineEvent struct {
+	kind lineKind
+	line string
+}
+
+type analyzeJob struct {
+	index int
+	root  string
+	path  string
+	// entry and content are set for the entries of an archive root
+	entry   string
+	content []byte
+}
+
+type analyzeResult struct {
+	analyzeJob
+	lang   string
+	file   *ClocFile
+	md5sum string
+	events []lineEvent
+}
+
 // Analyze executes gocloc parsing for the directory of the paths argument and returns the result.
+//
+// Files are analyzed by ClocOptions.Workers goroutines. The result does not
+// depend on the number of workers, and the OnCode, OnBlank and OnComment
+// callbacks are never called concurrently: they are invoked from a single
+// goroutine, file by file in walking order.
 func (p *Processor) Analyze(paths []string) (*Result, error) {
-	total := NewLanguage("TOTAL", []string{}, [][]string{{"", ""}})
-	languages, err := getAllFiles(paths, p.langs, p.opts)
-	if err != nil {
-		return nil, err
+	return p.analyze(paths, nil)
+}
+
+// AnalyzeFS executes gocloc parsing for the roots in fsys and returns the result.
+// The roots and the file names of the result are slash separated paths of fsys.
+func (p *Processor) AnalyzeFS(fsys fs.FS, roots []string) (*Result, error) {
+	fp := *p
+	fp.fsys = ioFileSystem{fsys: fsys}
+	return fp.Analyze(roots)
+}
+
+// analyze runs the analysis pipeline. When onFile is not nil, the lines of
+// the files are recorded and onFile is called for every counted file in walking order.
+func (p *Processor) analyze(paths []string, onFile func(r *analyzeResult)) (*Result, error) {
+	start := time.Now()
+	recordLines := onFile != nil || p.opts.OnCode != nil || p.opts.OnComment != nil || p.opts.OnBlank != nil
+
+	workers := p.opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
 	}
-	maxPathLen := 0
-	num := 0
-	for _, lang := range languages {
-		num += len(lang.Files)
-		for _, file := range lang.Files {
-			l := len(file)
-			if maxPathLen < l {
-				maxPathLen = l
+
+	jobs := make(chan analyzeJob, workers)
+	results := make(chan analyzeResult, workers)
+
+	// walker
+	var walkErr error
+	go func() {
+		defer close(jobs)
+		index := 0
+		for _, root := range paths {
+			var err error
+			if IsArchive(root) {
+				err = walkArchive(p.fsys, root, p.opts, func(entry string, content []byte) {
+					path := root + ArchiveSeparator + entry
+					jobs <- analyzeJob{index: index, root: root, path: path, entry: entry, content: content}
+					index++
+				})
+			} else {
+				err = walkFiles(p.fsys, []string{root}, p.opts, func(root, path string) {
+					jobs <- analyzeJob{index: index, root: root, path: path}
+					index++
+				})
+			}
+			if err != nil {
+				walkErr = err
 			}
 		}
+	}()
+
+	// workers
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				results <- p.analyzeJob(job, recordLines)
+			}
+		}()
 	}
-	clocFiles := make(map[string]*ClocFile, num)
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
 
-	for _, language := range languages {
-		for _, file := range language.Files {
-			cf := AnalyzeFile(file, language, p.opts)
-			cf.Lang = language.Name
-
-			language.Code += cf.Code
-			language.Comments += cf.Comments
-			language.Blanks += cf.Blanks
-			clocFiles[file] = cf
-		}
+	// aggregator
+	languages := make(map[string]*Language)
+	clocFiles := make(map[string]*ClocFile)
+	fileCache := make(map[string]struct{})
+	maxPathLen := 0
+	pending := make(map[int]analyzeResult)
+	next := 0
+	for r := range results {
+		pending[r.index] = r
+		for {
+			r, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+
+			if r.file == nil {
+				continue
+			}
+			if !p.opts.SkipDuplicated {
+				if ignore := checkMD5Sum(r.md5sum, fileCache); ignore {
+					if p.opts.Debug {
+						fmt.Printf("[ignore=%v] find same md5\n", r.path)
+					}
+					continue
+				}
+			}
 
-		files := int32(len(language.Files))
-		if len(language.Files) <= 0 {
-			continue
+			language, ok := languages[r.lang]
+			if !ok {
+				language = newLanguageFromDefinition(p.langs.Langs[r.lang])
+				languages[r.lang] = language
+			}
+			language.Files = append(language.Files, r.path)
+			language.Code += r.file.Code
+			language.Comments += r.file.Comments
+			language.Blanks += r.file.Blanks
+			clocFiles[r.path] = r.file
+			if l := len(r.path); maxPathLen < l {
+				maxPathLen = l
+			}
+			p.replayEvents(r.events)
+			if onFile != nil {
+				onFile(&r)
+			}
 		}
+	}
+	if walkErr != nil {
+		return nil, walkErr
+	}
 
-		total.Total += files
+	total := NewLanguage("TOTAL", []string{}, [][]string{{"", ""}})
+	for _, language := range languages {
+		total.Total += int32(len(language.Files))
 		total.Blanks += language.Blanks
 		total.Comments += language.Comments
 		total.Code += language.Code
@@ -69,5 +221,63 @@ func (p *Processor) Analyze(paths []string) (*Result, error) {
 		Files:         clocFiles,
 		Languages:     languages,
 		MaxPathLength: maxPathLen,
+		Elapsed:       time.Since(start),
 	}, nil
 }
+
+// analyzeJob reads the file once and counts its lines. When recordLines is
+// true, the lines are recorded instead of calling the callbacks of the
+// options, so that the aggregator can replay them in order.
+func (p *Processor) analyzeJob(job analyzeJob, recordLines bool) analyzeResult {
+	r := analyzeResult{analyzeJob: job}
+
+	var lang string
+	var content []byte
+	var ok bool
+	if job.content != nil {
+		lang, content, ok = readSourceFrom(job.entry, bytes.NewReader(job.content), p.langs, p.opts)
+	} else {
+		lang, content, ok = readSource(p.fsys, job.path, p.langs, p.opts)
+	}
+	if !ok {
+		return r
+	}
+	if !p.opts.SkipDuplicated {
+		r.md5sum = md5Sum(content)
+	}
+
+	opts := p.opts
+	if recordLines {
+		recordOpts := *opts
+		record := func(kind lineKind) func(string) {
+			return func(line string) {
+				r.events = append(r.events, lineEvent{kind: kind, line: line})
+			}
+		}
+		recordOpts.OnCode = record(lineCode)
+		recordOpts.OnComment = record(lineComment)
+		recordOpts.OnBlank = record(lineBlank)
+		opts = &recordOpts
+	}
+
+	r.lang = lang
+	r.file = AnalyzeReader(job.path, p.langs.Langs[lang], bytes.NewReader(content), opts)
+	return r
+}
+
+func (p *Processor) replayEvents(events []lineEvent) {
+	for _, e := range events {
+		var callback func(string)
+		switch e.kind {
+		case lineCode:
+			callback = p.opts.OnCode
+		case lineComment:
+			callback = p.opts.OnComment
+		case lineBlank:
+			callback = p.opts.OnBlank
+		}
+		if callback != nil {
+			callback(e.line)
+		}
+	}
+}
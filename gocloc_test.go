@@ -0,0 +1,136 @@
+package gocloc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func writeTestTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+			t.Fatalf("os.MkdirAll() error. err=[%v]", err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+			t.Fatalf("os.WriteFile() error. err=[%v]", err)
+		}
+	}
+	return root
+}
+
+func TestAnalyzeWithWorkers(t *testing.T) {
+	files := make(map[string]string)
+	for i := 0; i < 50; i++ {
+		files[fmt.Sprintf("pkg%d/file%d.go", i%7, i)] = fmt.Sprintf("package main\n\n// comment %d\nvar v%d = %d\n", i, i, i)
+		files[fmt.Sprintf("py/mod%d.py", i)] = strings.Repeat(fmt.Sprintf("# %d\nx = %d\n\n", i, i), i%5+1)
+	}
+	root := writeTestTree(t, files)
+
+	analyze := func(workers int) (*Result, []string) {
+		var lines []string
+		opts := NewClocOptions()
+		opts.Workers = workers
+		opts.OnCode = func(line string) { lines = append(lines, "code:"+line) }
+		opts.OnComment = func(line string) { lines = append(lines, "comment:"+line) }
+		opts.OnBlank = func(line string) { lines = append(lines, "blank:"+line) }
+
+		result, err := NewProcessor(NewDefinedLanguages(), opts).Analyze([]string{root})
+		if err != nil {
+			t.Fatalf("Analyze() error. err=[%v]", err)
+		}
+		return result, lines
+	}
+
+	serial, serialLines := analyze(1)
+	if serial.Total.Total != 100 {
+		t.Errorf("invalid logic. files=%v", serial.Total.Total)
+	}
+	if serial.Languages["Go"].Code != 100 || serial.Languages["Go"].Comments != 50 {
+		t.Errorf("invalid logic. go=%+v", serial.Languages["Go"])
+	}
+	if len(serialLines) != int(serial.Total.Code+serial.Total.Comments+serial.Total.Blanks) {
+		t.Errorf("invalid logic. callback lines=%v", len(serialLines))
+	}
+
+	for i := 0; i < 5; i++ {
+		parallel, parallelLines := analyze(8)
+		if !reflect.DeepEqual(serial.Total, parallel.Total) {
+			t.Errorf("invalid logic. total=%+v", parallel.Total)
+		}
+		if !reflect.DeepEqual(serial.Files, parallel.Files) {
+			t.Errorf("invalid logic. files differ")
+		}
+		for name, lang := range serial.Languages {
+			if !reflect.DeepEqual(lang.Files, parallel.Languages[name].Files) {
+				t.Errorf("invalid logic. files order differ. lang=%v", name)
+			}
+		}
+		if !reflect.DeepEqual(serialLines, parallelLines) {
+			t.Errorf("invalid logic. callback order differ")
+		}
+	}
+}
+
+func TestAnalyzeFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"src/.gitignore":     {Data: []byte("*.gen.go\n")},
+		"src/main.go":        {Data: []byte("package main\n\n// comment\nfunc main() {}\n")},
+		"src/dup.go":         {Data: []byte("package main\n\n// comment\nfunc main() {}\n")},
+		"src/main.gen.go":    {Data: []byte("package main\n")},
+		"src/tool":           {Data: []byte("#!/usr/bin/env python\n# comment\na = 1\n")},
+		"src/.git/config":    {Data: []byte("[core]\n")},
+		"src/web/app.js":     {Data: []byte("/* a */\nvar a = 1;\n")},
+		"other/lib.c":        {Data: []byte("int a;\n")},
+		"src/web/vendor.txt": {Data: []byte("text\n")},
+	}
+
+	opts := NewClocOptions()
+	opts.ExcludeExts["Plain Text"] = struct{}{}
+	result, err := NewProcessor(NewDefinedLanguages(), opts).AnalyzeFS(fsys, []string{"src"})
+	if err != nil {
+		t.Fatalf("AnalyzeFS() error. err=[%v]", err)
+	}
+
+	var files []string
+	for name := range result.Files {
+		files = append(files, name)
+	}
+	sort.Strings(files)
+	expected := []string{"src/dup.go", "src/tool", "src/web/app.js"}
+	if !equalStrings(files, expected) {
+		t.Errorf("invalid logic. files=%v", files)
+	}
+	if result.Languages["Python"] == nil {
+		t.Errorf("invalid logic. shebang is not detected")
+	}
+	if result.Total.Code != 5 || result.Total.Comments != 3 || result.Total.Blanks != 1 {
+		t.Errorf("invalid logic. total=%+v", result.Total)
+	}
+
+	result, err = NewProcessor(NewDefinedLanguages(), NewClocOptions()).AnalyzeFS(fsys, []string{"other/lib.c"})
+	if err != nil {
+		t.Fatalf("AnalyzeFS() error. err=[%v]", err)
+	}
+	if result.Total.Total != 1 || result.Files["other/lib.c"] == nil {
+		t.Errorf("invalid logic. files=%v", result.Files)
+	}
+}
+
+func TestResultThroughput(t *testing.T) {
+	result := &Result{
+		Total:   &Language{Total: 4, Code: 60, Comments: 10, Blanks: 10},
+		Elapsed: 2 * time.Second,
+	}
+	if result.FilesPerSecond() != 2 || result.LinesPerSecond() != 40 {
+		t.Errorf("invalid logic. files=%v lines=%v", result.FilesPerSecond(), result.LinesPerSecond())
+	}
+}
@@ -0,0 +1,266 @@
+package gocloc
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"time"
+)
+
+// HTMLResult defines the result of the analysis as a self-contained HTML page.
+type HTMLResult struct {
+	Title       string
+	Version     string
+	Languages   []ClocLanguage
+	Directories ClocDirectories
+	Total       ClocLanguage
+	Elapsed     time.Duration
+}
+
+// NewHTMLResultFromCloc returns HTMLResult with the languages and the files grouped by directory.
+func NewHTMLResultFromCloc(result *Result, sortedLanguages Languages, sortedFiles ClocFiles) *HTMLResult {
+	h := &HTMLResult{
+		Title:       "gocloc",
+		Directories: NewClocDirectories(sortedFiles),
+		Total: ClocLanguage{
+			Name:       "SUM",
+			FilesCount: result.Total.Total,
+			Code:       result.Total.Code,
+			Comments:   result.Total.Comments,
+			Blanks:     result.Total.Blanks,
+		},
+		Elapsed: result.Elapsed,
+	}
+	for _, language := range sortedLanguages {
+		h.Languages = append(h.Languages, ClocLanguage{
+			Name:       language.Name,
+			FilesCount: language.FilesCount(),
+			Code:       language.Code,
+			Comments:   language.Comments,
+			Blanks:     language.Blanks,
+		})
+	}
+	return h
+}
+
+// treemapRect is a tile of the treemap, positioned in percent of the map.
+type treemapRect struct {
+	Name   string
+	Code   int32
+	X, Y   float64
+	Width  float64
+	Height float64
+}
+
+// the aspect ratio of the treemap
+const (
+	treemapWidth  = 160.0
+	treemapHeight = 90.0
+)
+
+// treemap lays out the code lines of the directories as a squarified treemap.
+func (h *HTMLResult) treemap() []treemapRect {
+	dirs := make(ClocDirectories, 0, len(h.Directories))
+	for _, dir := range h.Directories {
+		if dir.Code > 0 {
+			dirs = append(dirs, dir)
+		}
+	}
+	dirs.SortByCode()
+
+	values := make([]float64, len(dirs))
+	for i, dir := range dirs {
+		values[i] = float64(dir.Code)
+	}
+	rects := squarify(values, treemapWidth, treemapHeight)
+	for i := range rects {
+		rects[i].Name = dirs[i].Name
+		rects[i].Code = dirs[i].Code
+		rects[i].X *= 100 / treemapWidth
+		rects[i].Width *= 100 / treemapWidth
+		rects[i].Y *= 100 / treemapHeight
+		rects[i].Height *= 100 / treemapHeight
+	}
+	return rects
+}
+
+// squarify lays out the values, sorted in descending order, in a width x height
+// rectangle with the squarified treemap algorithm of Bruls, Huizing and van Wijk.
+func squarify(values []float64, width, height float64) []treemapRect {
+	rects := make([]treemapRect, len(values))
+	var total float64
+	for _, v := range values {
+		total += v
+	}
+	if total == 0 {
+		return rects
+	}
+	scale := width * height / total
+
+	// worst returns the highest aspect ratio of a row of areas from largest to smallest.
+	worst := func(largest, smallest, sum, side float64) float64 {
+		return max(side*side*largest/(sum*sum), sum*sum/(side*side*smallest))
+	}
+
+	x, y := 0.0, 0.0
+	for i := 0; i < len(values); {
+		side := min(width, height)
+		largest := values[i] * scale
+		sum := largest
+		j := i + 1
+		for ; j < len(values); j++ {
+			area := values[j] * scale
+			if worst(largest, area, sum+area, side) > worst(largest, values[j-1]*scale, sum, side) {
+				break
+			}
+			sum += area
+		}
+
+		thickness := sum / side
+		pos := 0.0
+		for k := i; k < j; k++ {
+			length := values[k] * scale / thickness
+			if width >= height {
+				rects[k] = treemapRect{X: x, Y: y + pos, Width: thickness, Height: length}
+			} else {
+				rects[k] = treemapRect{X: x + pos, Y: y, Width: length, Height: thickness}
+			}
+			pos += length
+		}
+		if width >= height {
+			x += thickness
+			width -= thickness
+		} else {
+			y += thickness
+			height -= thickness
+		}
+		i = j
+	}
+	return rects
+}
+
+// commentRatio returns the percentage of comment lines in the comment and code lines.
+func commentRatio(comments, code int32) float64 {
+	if comments+code == 0 {
+		return 0
+	}
+	return float64(comments) * 100 / float64(comments+code)
+}
+
+var htmlTemplate = template.Must(template.New("html").Funcs(template.FuncMap{
+	"ratio": commentRatio,
+	"percent": func(f float64) string {
+		return fmt.Sprintf("%.2f", f)
+	},
+}).Parse(htmlLayout))
+
+// Encode writes the result as an HTML page without external assets.
+func (h *HTMLResult) Encode(w io.Writer) error {
+	return htmlTemplate.Execute(w, struct {
+		*HTMLResult
+		Treemap        []treemapRect
+		FilesPerSecond float64
+		LinesPerSecond float64
+	}{
+		HTMLResult:     h,
+		Treemap:        h.treemap(),
+		FilesPerSecond: perSecond(h.Total.FilesCount, h.Elapsed),
+		LinesPerSecond: perSecond(h.Total.Blanks+h.Total.Comments+h.Total.Code, h.Elapsed),
+	})
+}
+
+const htmlLayout = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+h1 { font-size: 1.6em; }
+h2 { font-size: 1.2em; margin-top: 2em; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { padding: 0.25em 0.75em; border-bottom: 1px solid #ddd; }
+th { background: #f4f4f4; text-align: left; }
+table.sortable th { cursor: pointer; user-select: none; }
+table.sortable th[data-order="asc"]::after { content: " \25B2"; }
+table.sortable th[data-order="desc"]::after { content: " \25BC"; }
+td.num, th.num { text-align: right; }
+tr.dir th { background: #e8eef7; }
+tr.total td { font-weight: bold; border-top: 2px solid #999; }
+.bar { width: 8em; height: 0.8em; background: #eee; }
+.bar div { height: 100%; background: #4a90d9; }
+.treemap { position: relative; width: 100%; max-width: 960px; aspect-ratio: 16 / 9; background: #eee; }
+.treemap div { position: absolute; box-sizing: border-box; overflow: hidden; border: 1px solid #fff; background: #4a90d9; color: #fff; font-size: 0.75em; padding: 0.2em; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>{{.Total.FilesCount}} files, {{.Total.Code}} code lines, {{.Total.Comments}} comment lines and {{.Total.Blanks}} blank lines
+in {{printf "%.2f" .Elapsed.Seconds}} s ({{printf "%.1f" .FilesPerSecond}} files/s, {{printf "%.1f" .LinesPerSecond}} lines/s).</p>
+
+<h2>Languages</h2>
+<table class="sortable">
+<thead>
+<tr><th>Language</th><th class="num">Files</th><th class="num">Blank</th><th class="num">Comment</th><th class="num">Code</th><th>Comment ratio</th></tr>
+</thead>
+<tbody>
+{{- range .Languages}}
+{{- $ratio := ratio .Comments .Code}}
+<tr class="row"><td data-value="{{.Name}}">{{.Name}}</td><td class="num" data-value="{{.FilesCount}}">{{.FilesCount}}</td><td class="num" data-value="{{.Blanks}}">{{.Blanks}}</td><td class="num" data-value="{{.Comments}}">{{.Comments}}</td><td class="num" data-value="{{.Code}}">{{.Code}}</td><td data-value="{{$ratio}}" title="{{percent $ratio}}%"><div class="bar"><div style="width: {{percent $ratio}}%"></div></div></td></tr>
+{{- end}}
+</tbody>
+<tfoot>
+{{- $ratio := ratio .Total.Comments .Total.Code}}
+<tr class="total"><td>SUM</td><td class="num">{{.Total.FilesCount}}</td><td class="num">{{.Total.Blanks}}</td><td class="num">{{.Total.Comments}}</td><td class="num">{{.Total.Code}}</td><td title="{{percent $ratio}}%"><div class="bar"><div style="width: {{percent $ratio}}%"></div></div></td></tr>
+</tfoot>
+</table>
+
+<h2>Code lines per directory</h2>
+<div class="treemap">
+{{- range .Treemap}}
+<div style="left: {{percent .X}}%; top: {{percent .Y}}%; width: {{percent .Width}}%; height: {{percent .Height}}%" title="{{.Name}}: {{.Code}}">{{.Name}}</div>
+{{- end}}
+</div>
+
+<h2>Files</h2>
+<table class="sortable">
+<thead>
+<tr><th>File</th><th>Language</th><th class="num">Blank</th><th class="num">Comment</th><th class="num">Code</th><th>Comment ratio</th></tr>
+</thead>
+{{- range .Directories}}
+<tbody>
+{{- $ratio := ratio .Comments .Code}}
+<tr class="dir"><th>{{.Name}}</th><th>{{.FilesCount}} files</th><th class="num">{{.Blanks}}</th><th class="num">{{.Comments}}</th><th class="num">{{.Code}}</th><th title="{{percent $ratio}}%"><div class="bar"><div style="width: {{percent $ratio}}%"></div></div></th></tr>
+{{- range .Files}}
+{{- $ratio := ratio .Comments .Code}}
+<tr class="row"><td data-value="{{.Name}}">{{.Name}}</td><td data-value="{{.Lang}}">{{.Lang}}</td><td class="num" data-value="{{.Blanks}}">{{.Blanks}}</td><td class="num" data-value="{{.Comments}}">{{.Comments}}</td><td class="num" data-value="{{.Code}}">{{.Code}}</td><td data-value="{{$ratio}}" title="{{percent $ratio}}%"><div class="bar"><div style="width: {{percent $ratio}}%"></div></div></td></tr>
+{{- end}}
+</tbody>
+{{- end}}
+</table>
+
+<p>Generated by gocloc {{.Version}}</p>
+
+<script>
+document.querySelectorAll("table.sortable").forEach(function (table) {
+  table.querySelectorAll("thead th").forEach(function (th, column) {
+    th.addEventListener("click", function () {
+      var asc = th.dataset.order !== "asc";
+      table.querySelectorAll("thead th").forEach(function (other) { delete other.dataset.order; });
+      th.dataset.order = asc ? "asc" : "desc";
+      table.querySelectorAll("tbody").forEach(function (tbody) {
+        var rows = Array.prototype.slice.call(tbody.querySelectorAll("tr.row"));
+        rows.sort(function (a, b) {
+          var x = a.cells[column].dataset.value, y = b.cells[column].dataset.value;
+          var c = isNaN(x) || isNaN(y) ? x.localeCompare(y) : x - y;
+          return asc ? c : -c;
+        });
+        rows.forEach(function (row) { tbody.appendChild(row); });
+      });
+    });
+  });
+});
+</script>
+</body>
+</html>
+`
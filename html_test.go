@@ -0,0 +1,63 @@
+package gocloc
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSquarify(t *testing.T) {
+	values := []float64{6, 6, 4, 3, 2, 2, 1}
+	rects := squarify(values, 6, 4)
+	for i, r := range rects {
+		if math.Abs(r.Width*r.Height-values[i]) > 1e-9 {
+			t.Errorf("invalid logic. value=%v rect=%+v", values[i], r)
+		}
+		if r.X < 0 || r.Y < 0 || r.X+r.Width > 6+1e-9 || r.Y+r.Height > 4+1e-9 {
+			t.Errorf("invalid logic. rect=%+v is out of bounds", r)
+		}
+	}
+	// the first row of the example in the paper
+	if rects[0].Width != 3 || rects[0].Height != 2 || rects[1].Y != 2 {
+		t.Errorf("invalid logic. rects=%+v", rects[:2])
+	}
+}
+
+func TestOutputHTML(t *testing.T) {
+	result := &Result{
+		Total:   &Language{Total: 2, Code: 12, Comments: 4, Blanks: 2},
+		Elapsed: time.Second,
+	}
+	languages := Languages{{Name: "Go", Files: []string{"a.go", "b/<c>.go"}, Code: 12, Comments: 4, Blanks: 2}}
+	files := ClocFiles{
+		{Name: "a.go", Lang: "Go", Code: 9, Comments: 3, Blanks: 1},
+		{Name: "b/<c>.go", Lang: "Go", Code: 3, Comments: 1, Blanks: 1},
+	}
+	htmlResult := NewHTMLResultFromCloc(result, languages, files)
+	htmlResult.Version = "v1.0.0"
+
+	var buf bytes.Buffer
+	if err := htmlResult.Encode(&buf); err != nil {
+		t.Fatalf("Encode() error. err=[%v]", err)
+	}
+	out := buf.String()
+	for _, expected := range []string{
+		"<title>gocloc</title>",
+		`<td data-value="Go">Go</td>`,
+		`title="b: 3"`,
+		`<div style="width: 25.00%">`,
+		"b/&lt;c&gt;.go",
+		"Generated by gocloc v1.0.0",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("invalid logic. %q is not in the output", expected)
+		}
+	}
+	for _, external := range []string{"<link", "src=", "http://", "https://"} {
+		if strings.Contains(out, external) {
+			t.Errorf("invalid logic. the output refers to an external asset: %q", external)
+		}
+	}
+}
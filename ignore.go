@@ -0,0 +1,307 @@
+package gocloc
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ignoreFileNames are the per-directory files holding gitignore-style patterns.
+// Later files take precedence over earlier ones in the same directory.
+var ignoreFileNames = []string{".gitignore", ".ignore", ".gocloc-ignore"}
+
+type ignorePattern struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// ignoreRules is a set of patterns whose paths are relative to base.
+type ignoreRules struct {
+	base     string
+	patterns []ignorePattern
+}
+
+// match reports whether the rules decide on the path (matched),
+// and if so whether the path is ignored.
+func (r *ignoreRules) match(p string, isDir bool) (ignored, matched bool) {
+	if r.base != "" {
+		if !strings.HasPrefix(p, r.base+"/") {
+			return false, false
+		}
+		p = p[len(r.base)+1:]
+	}
+	for _, pattern := range r.patterns {
+		if pattern.dirOnly && !isDir {
+			continue
+		}
+		if pattern.re.MatchString(p) {
+			ignored, matched = !pattern.negate, true
+		}
+	}
+	return ignored, matched
+}
+
+// ignoreMatcher evaluates gitignore-style rules while walking one root.
+// All paths handled by the matcher are slash separated and relative to top,
+// which is the enclosing git repository when there is one, otherwise the root.
+type ignoreMatcher struct {
+	fsys   fileSystem
+	top    string
+	prefix string
+	global []*ignoreRules
+	dirs   map[string][]*ignoreRules
+}
+
+func newIgnoreMatcher(fsys fileSystem, root string, opts *ClocOptions) *ignoreMatcher {
+	m := &ignoreMatcher{
+		fsys: fsys,
+		dirs: make(map[string][]*ignoreRules),
+	}
+
+	var exclude string
+	m.top, m.prefix, exclude = fsys.IgnoreRoot(root)
+	if exclude != "" {
+		if rules := loadIgnoreFile(fsys, exclude, ""); rules != nil {
+			m.global = append(m.global, rules)
+		}
+	}
+
+	for _, file := range opts.IgnoreFiles {
+		if rules := loadIgnoreFile(osFileSystem{}, file, m.prefix); rules != nil {
+			m.global = append(m.global, rules)
+		}
+	}
+
+	// directories between the repository root and the walked root
+	if m.prefix != "" {
+		dir := ""
+		for _, elem := range strings.Split(m.prefix, "/") {
+			m.loadDir(fsys.Join(m.top, dir), dir)
+			dir = path.Join(dir, elem)
+		}
+	}
+	return m
+}
+
+// key converts a path relative to the walked root into a matcher path.
+func (m *ignoreMatcher) key(rel string) string {
+	rel = filepath.ToSlash(rel)
+	if rel == "." {
+		rel = ""
+	}
+	if m.prefix == "" {
+		return rel
+	}
+	if rel == "" {
+		return m.prefix
+	}
+	return m.prefix + "/" + rel
+}
+
+// loadDir reads the ignore files placed in the directory dir.
+func (m *ignoreMatcher) loadDir(dir, key string) {
+	if _, ok := m.dirs[key]; ok {
+		return
+	}
+	var rules []*ignoreRules
+	for _, name := range ignoreFileNames {
+		if r := loadIgnoreFile(m.fsys, m.fsys.Join(dir, name), key); r != nil {
+			rules = append(rules, r)
+		}
+	}
+	m.dirs[key] = rules
+}
+
+// Ignored reports whether the path (matcher key) is excluded by any rule.
+// The rules closest to the path take precedence.
+func (m *ignoreMatcher) Ignored(key string, isDir bool) bool {
+	ignored := false
+	check := func(rules []*ignoreRules) {
+		for _, r := range rules {
+			if i, ok := r.match(key, isDir); ok {
+				ignored = i
+			}
+		}
+	}
+
+	check(m.global)
+	check(m.dirs[""])
+	for i := 0; i < len(key); i++ {
+		if key[i] == '/' {
+			check(m.dirs[key[:i]])
+		}
+	}
+	return ignored
+}
+
+// findGitRepository looks up the git repository containing dir and returns
+// its work tree and git directory.
+func findGitRepository(dir string) (root, gitDir string, ok bool) {
+	for {
+		dotGit := filepath.Join(dir, ".git")
+		if info, err := os.Stat(dotGit); err == nil {
+			if info.IsDir() {
+				return dir, dotGit, true
+			}
+			// worktrees and submodules use a file pointing to the git directory
+			if content, err := os.ReadFile(dotGit); err == nil {
+				line := strings.TrimSpace(string(content))
+				if strings.HasPrefix(line, "gitdir:") {
+					gitDir = strings.TrimSpace(strings.TrimPrefix(line, "gitdir:"))
+					if !filepath.IsAbs(gitDir) {
+						gitDir = filepath.Join(dir, gitDir)
+					}
+					return dir, gitDir, true
+				}
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", false
+		}
+		dir = parent
+	}
+}
+
+func loadIgnoreFile(fsys fileSystem, filename, base string) *ignoreRules {
+	fp, err := fsys.Open(filename)
+	if err != nil {
+		// most directories have no ignore files, which means no rules
+		if !errors.Is(err, fs.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+		}
+		return nil
+	}
+	defer fp.Close()
+	content, err := io.ReadAll(fp)
+	if err != nil {
+		return nil
+	}
+	rules := parseIgnoreRules(content, base)
+	if len(rules.patterns) == 0 {
+		return nil
+	}
+	return rules
+}
+
+// parseIgnoreRules parses the content of a gitignore formatted file.
+func parseIgnoreRules(content []byte, base string) *ignoreRules {
+	rules := &ignoreRules{base: base}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		if pattern, ok := parseIgnorePattern(scanner.Text()); ok {
+			rules.patterns = append(rules.patterns, pattern)
+		}
+	}
+	return rules
+}
+
+func parseIgnorePattern(line string) (pattern ignorePattern, ok bool) {
+	line = strings.TrimSuffix(line, "\r")
+	line = trimIgnoreTrailingSpaces(line)
+	if line == "" || line[0] == '#' {
+		return pattern, false
+	}
+
+	if line[0] == '!' {
+		pattern.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		pattern.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return pattern, false
+	}
+
+	// a slash at the beginning or in the middle anchors the pattern
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	var expr strings.Builder
+	expr.WriteString("^")
+	if !anchored {
+		expr.WriteString("(?:.*/)?")
+	}
+	expr.WriteString(globToRegexp(line))
+	expr.WriteString("$")
+
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		return pattern, false
+	}
+	pattern.re = re
+	return pattern, true
+}
+
+func trimIgnoreTrailingSpaces(line string) string {
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	return line
+}
+
+// globToRegexp converts a gitignore glob into a regular expression.
+func globToRegexp(glob string) string {
+	var expr strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				atStart := i == 0 || glob[i-1] == '/'
+				switch {
+				case atStart && i+2 < len(glob) && glob[i+2] == '/':
+					// "**/" matches zero or more directories
+					expr.WriteString("(?:.*/)?")
+					i += 2
+				case atStart && i+2 == len(glob):
+					// trailing "/**" matches everything inside
+					expr.WriteString(".*")
+					i++
+				default:
+					expr.WriteString("[^/]*")
+					i++
+				}
+				continue
+			}
+			expr.WriteString("[^/]*")
+		case '?':
+			expr.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				expr.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				c = glob[i]
+			}
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return expr.String()
+}
@@ -0,0 +1,151 @@
+package gocloc
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestParseIgnorePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{"*.log", "debug.log", false, true},
+		{"*.log", "logs/debug.log", false, true},
+		{"*.log", "debug.log.txt", false, false},
+		{"/debug.log", "debug.log", false, true},
+		{"/debug.log", "logs/debug.log", false, false},
+		{"logs/", "logs", true, true},
+		{"logs/", "logs", false, false},
+		{"logs/debug.log", "logs/debug.log", false, true},
+		{"logs/debug.log", "build/logs/debug.log", false, false},
+		{"**/logs", "build/logs", true, true},
+		{"**/logs", "logs", true, true},
+		{"**/logs/debug.log", "build/logs/debug.log", false, true},
+		{"logs/**", "logs/a/b/debug.log", false, true},
+		{"logs/**/debug.log", "logs/debug.log", false, true},
+		{"logs/**/debug.log", "logs/a/b/debug.log", false, true},
+		{"debug?.log", "debug1.log", false, true},
+		{"debug?.log", "debug10.log", false, false},
+		{"debug[0-9].log", "debug5.log", false, true},
+		{"debug[!0-9].log", "debug5.log", false, false},
+		{"debug[!0-9].log", "debuga.log", false, true},
+		{`\#file`, "#file", false, true},
+		{"node_modules", "web/node_modules", true, true},
+	}
+
+	for _, tt := range tests {
+		pattern, ok := parseIgnorePattern(tt.pattern)
+		if !ok {
+			t.Errorf("invalid pattern. pattern=%v", tt.pattern)
+			continue
+		}
+		rules := &ignoreRules{patterns: []ignorePattern{pattern}}
+		ignored, _ := rules.match(tt.path, tt.isDir)
+		if ignored != tt.ignored {
+			t.Errorf("invalid logic. pattern=%v path=%v ignored=%v", tt.pattern, tt.path, ignored)
+		}
+	}
+}
+
+func TestParseIgnorePatternSkipLines(t *testing.T) {
+	for _, line := range []string{"", "   ", "# comment", "/"} {
+		if _, ok := parseIgnorePattern(line); ok {
+			t.Errorf("invalid logic. line=[%v] should be skipped", line)
+		}
+	}
+}
+
+func TestIgnoreRulesNegation(t *testing.T) {
+	rules := parseIgnoreRules([]byte("*.log\n!important.log\n"), "")
+
+	if ignored, _ := rules.match("debug.log", false); !ignored {
+		t.Errorf("invalid logic. debug.log should be ignored")
+	}
+	if ignored, matched := rules.match("important.log", false); ignored || !matched {
+		t.Errorf("invalid logic. important.log should be re-included")
+	}
+}
+
+func TestGetAllFilesWithIgnoreFiles(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".git/info/exclude":      "excluded.go\n",
+		".gitignore":             "dist/\n*.gen.go\n!keep.gen.go\n",
+		".gocloc-ignore":         "/vendor\n",
+		"main.go":                "package main\n",
+		"excluded.go":            "package main\n",
+		"keep.gen.go":            "package main\n",
+		"drop.gen.go":            "package main\n",
+		"dist/bundle.js":         "var a = 1;\n",
+		"vendor/lib.go":          "package lib\n",
+		"sub/.gitignore":         "local.py\n",
+		"sub/local.py":           "a = 1\n",
+		"sub/app.py":             "b = 1\n",
+		"sub/vendor/vendored.go": "package vendored\n",
+	}
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+			t.Fatalf("os.MkdirAll() error. err=[%v]", err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+			t.Fatalf("os.WriteFile() error. err=[%v]", err)
+		}
+	}
+
+	collect := func(opts *ClocOptions) []string {
+		opts.SkipDuplicated = true
+		result, err := NewProcessor(NewDefinedLanguages(), opts).Analyze([]string{root})
+		if err != nil {
+			t.Fatalf("Analyze() error. err=[%v]", err)
+		}
+		var found []string
+		for _, lang := range result.Languages {
+			for _, file := range lang.Files {
+				rel, _ := filepath.Rel(root, file)
+				found = append(found, filepath.ToSlash(rel))
+			}
+		}
+		sort.Strings(found)
+		return found
+	}
+
+	expected := []string{"keep.gen.go", "main.go", "sub/app.py", "sub/vendor/vendored.go"}
+	if found := collect(NewClocOptions()); !equalStrings(found, expected) {
+		t.Errorf("invalid logic. files=%v", found)
+	}
+
+	opts := NewClocOptions()
+	opts.NoIgnore = true
+	if found := collect(opts); len(found) != 9 {
+		t.Errorf("invalid logic. --no-ignore files=%v", found)
+	}
+
+	extra := filepath.Join(t.TempDir(), "extra-ignore")
+	if err := os.WriteFile(extra, []byte("*.py\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() error. err=[%v]", err)
+	}
+	opts = NewClocOptions()
+	opts.IgnoreFiles = []string{extra}
+	expected = []string{"keep.gen.go", "main.go", "sub/vendor/vendored.go"}
+	if found := collect(opts); !equalStrings(found, expected) {
+		t.Errorf("invalid logic. --ignore-file files=%v", found)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
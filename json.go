@@ -1,5 +1,38 @@
 package gocloc
 
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+// ClocURL is the url written in the header of the cloc compatible formats.
+const ClocURL = "github.com/hhatto/gocloc"
+
+// ClocHeader is the header of the cloc compatible JSON and YAML formats.
+type ClocHeader struct {
+	URL            string  `json:"cloc_url"`
+	Version        string  `json:"cloc_version"`
+	ElapsedSeconds float64 `json:"elapsed_seconds"`
+	NFiles         int32   `json:"n_files"`
+	NLines         int32   `json:"n_lines"`
+	FilesPerSecond float64 `json:"files_per_second"`
+	LinesPerSecond float64 `json:"lines_per_second"`
+}
+
+// NewClocHeader returns ClocHeader of the total of an analysis which took elapsed.
+func NewClocHeader(total *Language, elapsed time.Duration) ClocHeader {
+	nLines := total.Blanks + total.Comments + total.Code
+	return ClocHeader{
+		URL:            ClocURL,
+		ElapsedSeconds: elapsed.Seconds(),
+		NFiles:         total.Total,
+		NLines:         nLines,
+		FilesPerSecond: perSecond(total.Total, elapsed),
+		LinesPerSecond: perSecond(nLines, elapsed),
+	}
+}
+
 // JSONLanguagesResult defines the result of the analysis in JSON format.
 type JSONLanguagesResult struct {
 	Languages []ClocLanguage `json:"languages"`
@@ -18,7 +51,7 @@ func NewJSONLanguagesResultFromCloc(total *Language, sortedLanguages Languages)
 	for _, language := range sortedLanguages {
 		c := ClocLanguage{
 			Name:       language.Name,
-			FilesCount: int32(len(language.Files)),
+			FilesCount: language.FilesCount(),
 			Code:       language.Code,
 			Comments:   language.Comments,
 			Blanks:     language.Blanks,
@@ -38,6 +71,44 @@ func NewJSONLanguagesResultFromCloc(total *Language, sortedLanguages Languages)
 	}
 }
 
+// JSONFilesLanguagesResult defines the result of the analysis(by files and by languages) in JSON format.
+type JSONFilesLanguagesResult struct {
+	Files     []ClocFile     `json:"files"`
+	Languages []ClocLanguage `json:"languages"`
+	Total     ClocLanguage   `json:"total"`
+}
+
+// NewJSONFilesLanguagesResultFromCloc returns JSONFilesLanguagesResult with default data set.
+func NewJSONFilesLanguagesResultFromCloc(total *Language, sortedFiles ClocFiles, sortedLanguages Languages) JSONFilesLanguagesResult {
+	langs := NewJSONLanguagesResultFromCloc(total, sortedLanguages)
+	return JSONFilesLanguagesResult{
+		Files:     sortedFiles,
+		Languages: langs.Languages,
+		Total:     langs.Total,
+	}
+}
+
+// JSONDirectoriesResult defines the result of the analysis(by directories) in JSON format.
+type JSONDirectoriesResult struct {
+	Directories []ClocLanguage `json:"directories"`
+	Total       ClocLanguage   `json:"total"`
+}
+
+// NewJSONDirectoriesResultFromCloc returns JSONDirectoriesResult with default data set.
+func NewJSONDirectoriesResultFromCloc(total *Language, sortedDirectories ClocDirectories) JSONDirectoriesResult {
+	t := ClocLanguage{
+		FilesCount: total.Total,
+		Code:       total.Code,
+		Comments:   total.Comments,
+		Blanks:     total.Blanks,
+	}
+
+	return JSONDirectoriesResult{
+		Directories: sortedDirectories.ClocLanguages(),
+		Total:       t,
+	}
+}
+
 // NewJSONFilesResultFromCloc returns JSONFilesResult with default data set.
 func NewJSONFilesResultFromCloc(total *Language, sortedFiles ClocFiles) JSONFilesResult {
 	t := ClocLanguage{
@@ -52,3 +123,146 @@ func NewJSONFilesResultFromCloc(total *Language, sortedFiles ClocFiles) JSONFile
 		Total: t,
 	}
 }
+
+// JSONDiffLanguagesResult defines the diff result of the analysis in JSON format.
+type JSONDiffLanguagesResult struct {
+	Languages []DiffLanguage `json:"languages"`
+	Total     DiffLanguage   `json:"total"`
+}
+
+// JSONDiffFilesResult defines the diff result of the analysis(by files) in JSON format.
+type JSONDiffFilesResult struct {
+	Files []DiffFile   `json:"files"`
+	Total DiffLanguage `json:"total"`
+}
+
+// NewJSONDiffLanguagesResult returns JSONDiffLanguagesResult with default data set.
+func NewJSONDiffLanguagesResult(total *DiffLanguage, sortedLanguages DiffLanguages) JSONDiffLanguagesResult {
+	t := *total
+	t.Name = ""
+
+	return JSONDiffLanguagesResult{
+		Languages: sortedLanguages,
+		Total:     t,
+	}
+}
+
+// NewJSONDiffFilesResult returns JSONDiffFilesResult with default data set.
+func NewJSONDiffFilesResult(total *DiffLanguage, sortedFiles DiffFiles) JSONDiffFilesResult {
+	t := *total
+	t.Name = ""
+
+	return JSONDiffFilesResult{
+		Files: sortedFiles,
+		Total: t,
+	}
+}
+
+// ClocJSONResult defines the result of the analysis in the JSON format of cloc --json:
+// a header object, an object for each language or file keyed by its name, and SUM.
+// Either Languages or Files is set.
+type ClocJSONResult struct {
+	Header    ClocHeader
+	Languages []ClocLanguage
+	Files     []ClocFile
+	Total     ClocLanguage
+}
+
+type clocJSONLanguage struct {
+	FilesCount int32 `json:"nFiles"`
+	Blanks     int32 `json:"blank"`
+	Comments   int32 `json:"comment"`
+	Code       int32 `json:"code"`
+}
+
+type clocJSONFile struct {
+	Blanks   int32  `json:"blank"`
+	Comments int32  `json:"comment"`
+	Code     int32  `json:"code"`
+	Lang     string `json:"language"`
+}
+
+type clocJSONSum struct {
+	Blanks     int32 `json:"blank"`
+	Comments   int32 `json:"comment"`
+	Code       int32 `json:"code"`
+	FilesCount int32 `json:"nFiles"`
+}
+
+func newClocJSONResult(total *Language, elapsed time.Duration) *ClocJSONResult {
+	return &ClocJSONResult{
+		Header: NewClocHeader(total, elapsed),
+		Total: ClocLanguage{
+			Name:       "SUM",
+			FilesCount: total.Total,
+			Code:       total.Code,
+			Comments:   total.Comments,
+			Blanks:     total.Blanks,
+		},
+	}
+}
+
+// NewClocJSONLanguagesResult returns ClocJSONResult with an object for each language.
+func NewClocJSONLanguagesResult(total *Language, sortedLanguages Languages, elapsed time.Duration) *ClocJSONResult {
+	result := newClocJSONResult(total, elapsed)
+	result.Languages = NewJSONLanguagesResultFromCloc(total, sortedLanguages).Languages
+	return result
+}
+
+// NewClocJSONDirectoriesResult returns ClocJSONResult with an object for each directory.
+func NewClocJSONDirectoriesResult(total *Language, sortedDirectories ClocDirectories, elapsed time.Duration) *ClocJSONResult {
+	result := newClocJSONResult(total, elapsed)
+	result.Languages = sortedDirectories.ClocLanguages()
+	return result
+}
+
+// NewClocJSONFilesResult returns ClocJSONResult with an object for each file.
+func NewClocJSONFilesResult(total *Language, sortedFiles ClocFiles, elapsed time.Duration) *ClocJSONResult {
+	result := newClocJSONResult(total, elapsed)
+	result.Files = sortedFiles
+	return result
+}
+
+// MarshalJSON encodes the result with its keys in order.
+func (c *ClocJSONResult) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	writeField := func(key string, value interface{}) error {
+		if buf.Len() == 0 {
+			buf.WriteByte('{')
+		} else {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return err
+		}
+		v, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+		return nil
+	}
+
+	if err := writeField("header", c.Header); err != nil {
+		return nil, err
+	}
+	for _, l := range c.Languages {
+		if err := writeField(l.Name, clocJSONLanguage{l.FilesCount, l.Blanks, l.Comments, l.Code}); err != nil {
+			return nil, err
+		}
+	}
+	for _, f := range c.Files {
+		if err := writeField(f.Name, clocJSONFile{f.Blanks, f.Comments, f.Code, f.Lang}); err != nil {
+			return nil, err
+		}
+	}
+	t := c.Total
+	if err := writeField("SUM", clocJSONSum{t.Blanks, t.Comments, t.Code, t.FilesCount}); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 )
 
 func TestOutputJSON(t *testing.T) {
@@ -36,3 +37,35 @@ func TestOutputJSON(t *testing.T) {
 		t.Errorf("invalid result. '%s'", resultJSONText)
 	}
 }
+
+func TestOutputClocJSON(t *testing.T) {
+	total := &Language{Total: 3, Code: 30, Comments: 6, Blanks: 4}
+	languages := Languages{
+		{Name: "Go", Files: []string{"a.go", "b.go"}, Code: 20, Comments: 5, Blanks: 3},
+		{Name: "Python", Files: []string{"c.py"}, Code: 10, Comments: 1, Blanks: 1},
+	}
+	result := NewClocJSONLanguagesResult(total, languages, 2*time.Second)
+	result.Header.Version = "v1.0.0"
+
+	buf, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("json.Marshal() error. err=[%v]", err)
+	}
+	expected := `{"header":{"cloc_url":"github.com/hhatto/gocloc","cloc_version":"v1.0.0","elapsed_seconds":2,"n_files":3,"n_lines":40,"files_per_second":1.5,"lines_per_second":20},` +
+		`"Go":{"nFiles":2,"blank":3,"comment":5,"code":20},"Python":{"nFiles":1,"blank":1,"comment":1,"code":10},` +
+		`"SUM":{"blank":4,"comment":6,"code":30,"nFiles":3}}`
+	if string(buf) != expected {
+		t.Errorf("invalid result. '%s'", buf)
+	}
+
+	files := ClocFiles{{Name: "a.go", Lang: "Go", Code: 1, Comments: 2, Blanks: 3}}
+	buf, err = json.Marshal(NewClocJSONFilesResult(&Language{Total: 1, Code: 1, Comments: 2, Blanks: 3}, files, 0))
+	if err != nil {
+		t.Fatalf("json.Marshal() error. err=[%v]", err)
+	}
+	expected = `{"header":{"cloc_url":"github.com/hhatto/gocloc","cloc_version":"","elapsed_seconds":0,"n_files":1,"n_lines":6,"files_per_second":0,"lines_per_second":0},` +
+		`"a.go":{"blank":3,"comment":2,"code":1,"language":"Go"},"SUM":{"blank":3,"comment":2,"code":1,"nFiles":1}}`
+	if string(buf) != expected {
+		t.Errorf("invalid result. '%s'", buf)
+	}
+}
@@ -0,0 +1,368 @@
+package gocloc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LanguageDefinition is the native (YAML or JSON) form of a language definition.
+type LanguageDefinition struct {
+	Extensions        []string        `yaml:"extensions" json:"extensions"`
+	Filenames         []string        `yaml:"filenames" json:"filenames"`
+	Interpreters      []string        `yaml:"interpreters" json:"interpreters"`
+	LineComments      []string        `yaml:"line_comments" json:"line_comments"`
+	RegexLineComments []string        `yaml:"regex_line_comments" json:"regex_line_comments"`
+	MultiLineComments [][]string      `yaml:"multi_line_comments" json:"multi_line_comments"`
+	NestedComments    []string        `yaml:"nested_comments" json:"nested_comments"`
+	StringLiterals    []StringLiteral `yaml:"string_literals" json:"string_literals"`
+	Docstrings        bool            `yaml:"docstrings" json:"docstrings"`
+	Scale             float64         `yaml:"3rd_gen_scale" json:"3rd_gen_scale"`
+}
+
+// LoadDefinedLanguages returns the languages defined by r only,
+// without the built-in definitions (cloc's --force-lang-def).
+func LoadDefinedLanguages(r io.Reader) (*DefinedLanguages, error) {
+	langs := &DefinedLanguages{
+		Langs:     make(map[string]*Language),
+		noBuiltin: true,
+	}
+	if err := langs.Load(r); err != nil {
+		return nil, err
+	}
+	return langs, nil
+}
+
+// Load reads language definitions from r and merges them into langs
+// (cloc's --read-lang-def). A loaded language replaces the definition of the
+// same name, and its extensions and file names take precedence over the built-in ones.
+//
+// r holds either cloc's language definition format, as written by
+// cloc --write-lang-def, or a YAML or JSON map of LanguageDefinition by language name.
+func (langs *DefinedLanguages) Load(r io.Reader) error {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	var defs map[string]*LanguageDefinition
+	var names []string
+	if isClocLangDef(content) {
+		defs, names, err = parseClocLangDef(content)
+	} else {
+		defs, names, err = parseNativeLangDef(content)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if err := langs.define(name, defs[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (langs *DefinedLanguages) define(name string, def *LanguageDefinition) error {
+	multiLines := def.MultiLineComments
+	for _, ml := range multiLines {
+		if len(ml) != 2 {
+			return fmt.Errorf("%s: multi-line comment needs a begin and an end marker: %v", name, ml)
+		}
+	}
+	if len(multiLines) == 0 {
+		multiLines = [][]string{{"", ""}}
+	}
+	lineComments := def.LineComments
+	if lineComments == nil {
+		lineComments = []string{}
+	}
+
+	lang := NewLanguage(name, lineComments, multiLines)
+	// Perl expressions which RE2 does not support, such as lookarounds, are skipped
+	var regexLineComments []string
+	for _, expr := range def.RegexLineComments {
+		if _, err := regexp.Compile(expr); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: skip the line comment %q: %v\n", name, expr, err)
+			continue
+		}
+		regexLineComments = append(regexLineComments, expr)
+	}
+	if len(regexLineComments) > 0 {
+		// the regular expressions replace the line comments in the analysis, so they include them
+		var exprs []string
+		for _, marker := range lineComments {
+			exprs = append(exprs, `^\s*`+regexp.QuoteMeta(marker))
+		}
+		lang.WithRegexLineComments(append(exprs, regexLineComments...))
+	}
+	if len(def.NestedComments) > 0 {
+		lang.WithNestedComments(def.NestedComments...)
+	}
+	if len(def.StringLiterals) > 0 {
+		lang.WithStringLiterals(def.StringLiterals...)
+	}
+	if def.Docstrings {
+		lang.WithDocstrings()
+	}
+	lang.Scale = def.Scale
+	langs.Langs[name] = lang
+
+	if langs.exts == nil {
+		langs.exts = make(map[string]string)
+		langs.filenames = make(map[string]string)
+		langs.scripts = make(map[string]string)
+	}
+	for _, ext := range def.Extensions {
+		langs.exts[strings.TrimPrefix(ext, ".")] = name
+	}
+	for _, filename := range def.Filenames {
+		langs.filenames[filename] = name
+	}
+	for _, exe := range def.Interpreters {
+		langs.scripts[exe] = name
+	}
+	return nil
+}
+
+// LanguageOfExt returns the name of the language of the file name extension.
+func (langs *DefinedLanguages) LanguageOfExt(ext string) (lang string, ok bool) {
+	if lang, ok = langs.exts[ext]; ok || langs.noBuiltin {
+		return lang, ok
+	}
+	lang, ok = Exts[ext]
+	return lang, ok
+}
+
+// detectLanguage returns the name of the language of the file, looking up
+// the loaded definitions before the built-in detection.
+func (langs *DefinedLanguages) detectLanguage(path string, head []byte, opts *ClocOptions) (lang string, ok bool) {
+	if lang, ok = langs.filenames[filepath.Base(path)]; ok {
+		return lang, true
+	}
+	if ext := filepath.Ext(path); len(ext) > 1 {
+		if lang, ok = langs.exts[ext[1:]]; ok {
+			return lang, true
+		}
+	}
+	if len(langs.scripts) > 0 {
+		if exe, ok := getFileTypeByShebang(head); ok {
+			if lang, ok = langs.scripts[exe]; ok {
+				return lang, true
+			}
+		}
+	}
+	if langs.noBuiltin {
+		return "", false
+	}
+
+	ext, ok := getFileType(path, head, opts)
+	if !ok {
+		return "", false
+	}
+	lang, ok = Exts[ext]
+	return lang, ok
+}
+
+// langExts returns the extensions of the language, separated by commas.
+func (langs *DefinedLanguages) langExts(lang string) string {
+	var exts []string
+	if !langs.noBuiltin {
+		if builtin := lang2exts(lang); builtin != "" {
+			exts = append(exts, builtin)
+		}
+	}
+	var loaded []string
+	for ext, l := range langs.exts {
+		if l == lang {
+			loaded = append(loaded, ext)
+		}
+	}
+	sort.Strings(loaded)
+	return strings.Join(append(exts, loaded...), ", ")
+}
+
+func parseNativeLangDef(content []byte) (map[string]*LanguageDefinition, []string, error) {
+	var defs map[string]*LanguageDefinition
+	if err := yaml.Unmarshal(content, &defs); err != nil {
+		return nil, nil, fmt.Errorf("language definition: %v", err)
+	}
+	var names []string
+	for name, def := range defs {
+		if def == nil {
+			defs[name] = &LanguageDefinition{}
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return defs, names, nil
+}
+
+var reClocLangDefAttribute = regexp.MustCompile(`(?m)^\s+(filter|extension|filename|script_exe|3rd_gen_scale|end_of_line_continuation)\s`)
+
+// isClocLangDef reports whether the content is in cloc's language definition format.
+func isClocLangDef(content []byte) bool {
+	trimmed := bytes.TrimSpace(content)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		return false
+	}
+	return reClocLangDefAttribute.Match(content)
+}
+
+// parseClocLangDef parses cloc's language definition format:
+//
+//	Python
+//	    filter remove_matches ^\s*#
+//	    filter docstring_to_C
+//	    extension py
+//	    3rd_gen_scale 4.20
+//
+// Filters without an equivalent in gocloc, such as remove_inline, are ignored.
+func parseClocLangDef(content []byte) (map[string]*LanguageDefinition, []string, error) {
+	defs := make(map[string]*LanguageDefinition)
+	var names []string
+	var def *LanguageDefinition
+	var name string
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if line[0] != ' ' && line[0] != '\t' {
+			name = line
+			if def = defs[name]; def == nil {
+				def = &LanguageDefinition{}
+				defs[name] = def
+				names = append(names, name)
+			}
+			continue
+		}
+		if def == nil {
+			return nil, nil, fmt.Errorf("language definition:%d: attribute without language", lineNo)
+		}
+
+		fields := strings.Fields(line)
+		switch fields[0] {
+		case "extension":
+			def.Extensions = append(def.Extensions, fields[1:]...)
+		case "filename":
+			def.Filenames = append(def.Filenames, fields[1:]...)
+		case "script_exe":
+			def.Interpreters = append(def.Interpreters, fields[1:]...)
+		case "3rd_gen_scale":
+			if len(fields) != 2 {
+				return nil, nil, fmt.Errorf("language definition:%d: invalid 3rd_gen_scale", lineNo)
+			}
+			scale, err := strconv.ParseFloat(fields[1], 64)
+			if err != nil {
+				return nil, nil, fmt.Errorf("language definition:%d: %v", lineNo, err)
+			}
+			def.Scale = scale
+		case "filter":
+			if len(fields) < 2 {
+				return nil, nil, fmt.Errorf("language definition:%d: filter without name", lineNo)
+			}
+			applyClocFilter(name, def, fields[1], fields[2:])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	return defs, names, nil
+}
+
+// clocCommonComments are the comments of Regexp::Common used by call_regexp_common.
+var clocCommonComments = map[string]struct {
+	lineComments []string
+	multiLines   [][]string
+}{
+	"C":       {nil, [][]string{{"/*", "*/"}}},
+	"C++":     {[]string{"//"}, [][]string{{"/*", "*/"}}},
+	"HTML":    {nil, [][]string{{"<!--", "-->"}}},
+	"Pascal":  {nil, [][]string{{"{", "}"}, {"(*", "*)"}}},
+	"Haskell": {[]string{"--"}, [][]string{{"{-", "-}"}}},
+}
+
+var reClocLineCommentFilter = regexp.MustCompile(`^\^\\s\*(.+)$`)
+
+func applyClocFilter(name string, def *LanguageDefinition, filter string, args []string) {
+	switch filter {
+	case "remove_matches":
+		if len(args) == 0 {
+			return
+		}
+		expr := strings.Join(args, " ")
+		if m := reClocLineCommentFilter.FindStringSubmatch(expr); m != nil {
+			if marker, ok := unquoteRegexp(m[1]); ok {
+				def.LineComments = append(def.LineComments, marker)
+				return
+			}
+		}
+		def.RegexLineComments = append(def.RegexLineComments, expr)
+	case "remove_between_general", "remove_between_regex", "remove_between_nested_general":
+		if len(args) != 2 {
+			return
+		}
+		begin, end := args[0], args[1]
+		if filter == "remove_between_regex" {
+			var okBegin, okEnd bool
+			begin, okBegin = unquoteRegexp(begin)
+			end, okEnd = unquoteRegexp(end)
+			if !okBegin || !okEnd {
+				return
+			}
+		}
+		def.MultiLineComments = append(def.MultiLineComments, []string{begin, end})
+		if filter == "remove_between_nested_general" {
+			def.NestedComments = append(def.NestedComments, begin)
+		}
+	case "remove_html_comments":
+		def.MultiLineComments = append(def.MultiLineComments, []string{"<!--", "-->"})
+	case "call_regexp_common":
+		for _, arg := range args {
+			if common, ok := clocCommonComments[arg]; ok {
+				def.LineComments = append(def.LineComments, common.lineComments...)
+				def.MultiLineComments = append(def.MultiLineComments, common.multiLines...)
+			}
+		}
+	case "docstring_to_C":
+		def.Docstrings = true
+	}
+}
+
+// unquoteRegexp returns the literal matched by expr, if expr has no other meaning.
+func unquoteRegexp(expr string) (string, bool) {
+	var literal strings.Builder
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		if c == '\\' {
+			if i+1 == len(expr) {
+				return "", false
+			}
+			i++
+			c = expr[i]
+			if ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') {
+				// character classes such as \s
+				return "", false
+			}
+		} else if strings.IndexByte(`.+*?()|[]{}^$`, c) >= 0 {
+			return "", false
+		}
+		literal.WriteByte(c)
+	}
+	return literal.String(), literal.Len() > 0
+}
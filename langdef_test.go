@@ -0,0 +1,214 @@
+package gocloc
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const testClocLangDef = `Foo Script
+    filter remove_matches ^\s*;;
+    filter remove_inline ;;.*$
+    filter call_regexp_common C
+    extension foo
+    extension fooh
+    filename Foofile
+    script_exe foorun
+    3rd_gen_scale 2.50
+Go
+    filter remove_matches ^\s*//
+    filter remove_between_nested_general {| |}
+    extension go
+Bar
+    filter remove_matches ^\s*(rem|REM)\b
+    filter remove_between_regex <\# \#>
+    extension bar
+`
+
+func TestLoadClocLangDef(t *testing.T) {
+	langs := NewDefinedLanguages()
+	if err := langs.Load(strings.NewReader(testClocLangDef)); err != nil {
+		t.Fatalf("Load() error. err=[%v]", err)
+	}
+
+	foo, ok := langs.Langs["Foo Script"]
+	if !ok {
+		t.Fatalf("invalid logic. Foo Script is not defined")
+	}
+	if !equalStrings(foo.lineComments, []string{";;"}) {
+		t.Errorf("invalid logic. lineComments=%v", foo.lineComments)
+	}
+	if len(foo.multiLines) != 1 || foo.multiLines[0][0] != "/*" || foo.multiLines[0][1] != "*/" {
+		t.Errorf("invalid logic. multiLines=%v", foo.multiLines)
+	}
+	if foo.Scale != 2.5 {
+		t.Errorf("invalid logic. scale=%v", foo.Scale)
+	}
+
+	goLang := langs.Langs["Go"]
+	if !goLang.isNestedComment("{|") || goLang.multiLines[0][0] != "{|" {
+		t.Errorf("invalid logic. Go multiLines=%v nested=%v", goLang.multiLines, goLang.nestedComments)
+	}
+
+	bar := langs.Langs["Bar"]
+	if len(bar.regexLineComments) != 1 || !bar.regexLineComments[0].MatchString("rem a comment") {
+		t.Errorf("invalid logic. regexLineComments=%v", bar.regexLineComments)
+	}
+	if len(bar.multiLines) != 1 || bar.multiLines[0][0] != "<#" || bar.multiLines[0][1] != "#>" {
+		t.Errorf("invalid logic. multiLines=%v", bar.multiLines)
+	}
+
+	// the built-in languages are kept
+	if _, ok := langs.Langs["Python"]; !ok {
+		t.Errorf("invalid logic. built-in languages are removed")
+	}
+
+	opts := NewClocOptions()
+	tests := []struct {
+		path string
+		head string
+		lang string
+	}{
+		{"a/main.foo", "", "Foo Script"},
+		{"a/main.fooh", "", "Foo Script"},
+		{"a/Foofile", "", "Foo Script"},
+		{"a/script", "#!/usr/bin/env foorun\n", "Foo Script"},
+		{"a/main.bar", "", "Bar"},
+		{"a/main.py", "", "Python"},
+	}
+	for _, tt := range tests {
+		if lang, _ := langs.detectLanguage(tt.path, []byte(tt.head), opts); lang != tt.lang {
+			t.Errorf("invalid logic. path=%v lang=%v", tt.path, lang)
+		}
+	}
+	if lang, _ := langs.LanguageOfExt("foo"); lang != "Foo Script" {
+		t.Errorf("invalid logic. LanguageOfExt(foo)=%v", lang)
+	}
+}
+
+func TestLoadDefinedLanguagesNative(t *testing.T) {
+	tests := []string{
+		`Foo Script:
+  extensions: [foo]
+  line_comments: [";;"]
+  multi_line_comments: [["/*", "*/"]]
+  string_literals:
+    - {begin: '"', end: '"', escape: '\'}
+`,
+		`{"Foo Script": {"extensions": ["foo"], "line_comments": [";;"], "multi_line_comments": [["/*", "*/"]],
+  "string_literals": [{"begin": "\"", "end": "\"", "escape": "\\"}]}}`,
+	}
+
+	for _, def := range tests {
+		langs, err := LoadDefinedLanguages(strings.NewReader(def))
+		if err != nil {
+			t.Fatalf("LoadDefinedLanguages() error. err=[%v]", err)
+		}
+
+		var names []string
+		for name := range langs.Langs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if !equalStrings(names, []string{"Foo Script"}) {
+			t.Errorf("invalid logic. langs=%v", names)
+		}
+		if _, ok := langs.detectLanguage("main.py", nil, NewClocOptions()); ok {
+			t.Errorf("invalid logic. built-in languages are detected")
+		}
+		if _, ok := langs.LanguageOfExt("py"); ok {
+			t.Errorf("invalid logic. built-in extensions are found")
+		}
+
+		lang, ok := langs.detectLanguage("main.foo", nil, NewClocOptions())
+		if !ok {
+			t.Fatalf("invalid logic. main.foo is not detected")
+		}
+		source := ";; comment\nx = \"/*\"\n/* block\n*/\n\ny = 1\n"
+		clocFile := AnalyzeReader("main.foo", newLanguageFromDefinition(langs.Langs[lang]), bytes.NewBufferString(source), NewClocOptions())
+		if clocFile.Code != 2 || clocFile.Comments != 3 || clocFile.Blanks != 1 {
+			t.Errorf("invalid logic. code=%v comments=%v blanks=%v", clocFile.Code, clocFile.Comments, clocFile.Blanks)
+		}
+	}
+}
+
+func TestLoadClocLangDefMixedLineComments(t *testing.T) {
+	langs := NewDefinedLanguages()
+	def := "DOS Batch\n    filter remove_matches ^\\s*rem\\s\n    filter remove_matches ^\\s*::\n    extension bat\n"
+	if err := langs.Load(strings.NewReader(def)); err != nil {
+		t.Fatalf("Load() error. err=[%v]", err)
+	}
+
+	batch := langs.Langs["DOS Batch"]
+	if !equalStrings(batch.lineComments, []string{"::"}) || len(batch.regexLineComments) != 2 {
+		t.Errorf("invalid logic. lineComments=%v regexLineComments=%v", batch.lineComments, batch.regexLineComments)
+	}
+	source := "rem comment\n:: comment\necho 1\n"
+	clocFile := AnalyzeReader("main.bat", newLanguageFromDefinition(batch), bytes.NewBufferString(source), NewClocOptions())
+	if clocFile.Code != 1 || clocFile.Comments != 2 {
+		t.Errorf("invalid logic. code=%v comments=%v", clocFile.Code, clocFile.Comments)
+	}
+}
+
+func TestLoadLangDefSkipsInvalidRegexp(t *testing.T) {
+	def := "Foo\n    filter remove_matches ^\\s*#(?!!)\n    filter remove_matches ^\\s*;\n    extension foo\n" +
+		"Bar\n    filter remove_matches ^\\s*//\n    extension bar\n"
+	langs := NewDefinedLanguages()
+	if err := langs.Load(strings.NewReader(def)); err != nil {
+		t.Fatalf("Load() error. err=[%v]", err)
+	}
+
+	foo := langs.Langs["Foo"]
+	if foo == nil || len(foo.regexLineComments) != 0 || !equalStrings(foo.lineComments, []string{";"}) {
+		t.Errorf("invalid logic. foo=%+v", foo)
+	}
+	if _, ok := langs.Langs["Bar"]; !ok {
+		t.Errorf("invalid logic. Bar is not defined")
+	}
+
+	native := "Foo:\n  line_comments: [\";\"]\n  regex_line_comments: [\"(\", \"^#\"]\n"
+	langs = NewDefinedLanguages()
+	if err := langs.Load(strings.NewReader(native)); err != nil {
+		t.Fatalf("Load() error. err=[%v]", err)
+	}
+	if foo := langs.Langs["Foo"]; len(foo.regexLineComments) != 2 || !foo.regexLineComments[1].MatchString("# a") {
+		t.Errorf("invalid logic. regexLineComments=%v", foo.regexLineComments)
+	}
+}
+
+func TestLoadLangDefErrors(t *testing.T) {
+	tests := []string{
+		"    extension foo\n",
+		"Foo\n    3rd_gen_scale high\n",
+		"Foo:\n  multi_line_comments: [[\"/*\"]]\n",
+		"- not a map\n",
+	}
+	for _, def := range tests {
+		if err := NewDefinedLanguages().Load(strings.NewReader(def)); err == nil {
+			t.Errorf("invalid logic. definition=%q should be an error", def)
+		}
+	}
+}
+
+func TestUnquoteRegexp(t *testing.T) {
+	tests := []struct {
+		expr    string
+		literal string
+		ok      bool
+	}{
+		{`//`, "//", true},
+		{`\#`, "#", true},
+		{`\/\*`, "/*", true},
+		{`--`, "--", true},
+		{`\s*#`, "", false},
+		{`(rem|REM)`, "", false},
+		{`#.*`, "", false},
+	}
+	for _, tt := range tests {
+		literal, ok := unquoteRegexp(tt.expr)
+		if literal != tt.literal || ok != tt.ok {
+			t.Errorf("invalid logic. expr=%v literal=%v ok=%v", tt.expr, literal, ok)
+		}
+	}
+}
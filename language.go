@@ -1,10 +1,8 @@
 package gocloc
 
 import (
-	"bufio"
 	"bytes"
 	"fmt"
-	"os"
 	"path/filepath"
 	"regexp"
 	"sort"
@@ -29,11 +27,49 @@ type Language struct {
 	lineComments      []string
 	regexLineComments []*regexp.Regexp
 	multiLines        [][]string
+	stringLiterals    []StringLiteral
+	nestedComments    []string
+	docstrings        bool
 	Files             []string
 	Code              int32
 	Comments          int32
 	Blanks            int32
 	Total             int32
+	// Scale is the 3rd generation language scale factor, zero when unknown.
+	Scale float64
+}
+
+// FilesCount returns the number of files of the language. It is Total when
+// some file names are unknown, as in a result read from a language report.
+func (l *Language) FilesCount() int32 {
+	return max(int32(len(l.Files)), l.Total)
+}
+
+// StringLiteral is the syntax of a string or character literal.
+// Comment markers inside a literal are part of the code.
+type StringLiteral struct {
+	Begin string `yaml:"begin" json:"begin"`
+	End   string `yaml:"end" json:"end"`
+	// Escape is the prefix escaping the next character, empty for raw strings.
+	Escape string `yaml:"escape" json:"escape"`
+	// MultiLine is true if the literal may span several lines.
+	MultiLine bool `yaml:"multi_line" json:"multi_line"`
+}
+
+// skip returns the position just after the end of the literal starting
+// in line at pos, or the length of the line if the literal is not closed.
+func (s *StringLiteral) skip(line string, pos int) (next int, closed bool) {
+	for pos < len(line) {
+		if s.Escape != "" && strings.HasPrefix(line[pos:], s.Escape) {
+			pos += len(s.Escape) + 1
+			continue
+		}
+		if strings.HasPrefix(line[pos:], s.End) {
+			return pos + len(s.End), true
+		}
+		pos++
+	}
+	return len(line), false
 }
 
 // Languages is an array representation of Language.
@@ -48,10 +84,10 @@ func (ls Languages) SortByName() {
 
 func (ls Languages) SortByFiles() {
 	sortFunc := func(i, j int) bool {
-		if len(ls[i].Files) == len(ls[j].Files) {
+		if ls[i].FilesCount() == ls[j].FilesCount() {
 			return ls[i].Code > ls[j].Code
 		}
-		return len(ls[i].Files) > len(ls[j].Files)
+		return ls[i].FilesCount() > ls[j].FilesCount()
 	}
 	sort.Slice(ls, sortFunc)
 }
@@ -374,16 +410,12 @@ func getShebang(line string) (shebangLang string, ok bool) {
 	return "", false
 }
 
-func getFileTypeByShebang(path string) (shebangLang string, ok bool) {
-	f, err := os.Open(path)
-	if err != nil {
-		return // ignore error
-	}
-	defer f.Close()
-
-	reader := bufio.NewReader(f)
-	line, err := reader.ReadBytes('\n')
-	if err != nil {
+func getFileTypeByShebang(head []byte) (shebangLang string, ok bool) {
+	line := head
+	if i := bytes.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i+1]
+	} else {
+		// the first line must be terminated
 		return
 	}
 	line = bytes.TrimLeftFunc(line, unicode.IsSpace)
@@ -394,26 +426,30 @@ func getFileTypeByShebang(path string) (shebangLang string, ok bool) {
 	return
 }
 
-func getFileType(path string, opts *ClocOptions) (ext string, ok bool) {
+// isDetectedByContent reports whether the file type of path is ambiguous
+// and has to be detected from the whole content of the file.
+func isDetectedByContent(path string) bool {
+	switch filepath.Ext(path) {
+	case ".m", ".v", ".fs", ".r", ".ts", ".mo":
+		return true
+	}
+	return false
+}
+
+// getFileType detects the file type of path. content holds the whole file
+// when isDetectedByContent(path) is true, otherwise at least its first line.
+func getFileType(path string, content []byte, opts *ClocOptions) (ext string, ok bool) {
 	ext = filepath.Ext(path)
 	base := filepath.Base(path)
 
 	switch ext {
 	case ".m", ".v", ".fs", ".r", ".ts":
-		content, err := os.ReadFile(path)
-		if err != nil {
-			return "", false
-		}
 		lang := enry.GetLanguage(path, content)
 		if opts.Debug {
 			fmt.Printf("path=%v, lang=%v\n", path, lang)
 		}
 		return lang, true
 	case ".mo":
-		content, err := os.ReadFile(path)
-		if err != nil {
-			return "", false
-		}
 		lang := enry.GetLanguage(path, content)
 		if opts.Debug {
 			fmt.Printf("path=%v, lang=%v\n", path, lang)
@@ -454,7 +490,7 @@ func getFileType(path string, opts *ClocOptions) (ext string, ok bool) {
 		return "Dockerfile", true
 	}
 
-	shebangLang, ok := getFileTypeByShebang(path)
+	shebangLang, ok := getFileTypeByShebang(content)
 	if ok {
 		return shebangLang, true
 	}
@@ -475,6 +511,19 @@ func NewLanguage(name string, lineComments []string, multiLines [][]string) *Lan
 	}
 }
 
+// newLanguageFromDefinition returns an empty data store sharing the syntax of the defined language.
+func newLanguageFromDefinition(definedLang *Language) *Language {
+	lang := NewLanguage(definedLang.Name, definedLang.lineComments, definedLang.multiLines)
+	if len(definedLang.regexLineComments) > 0 {
+		lang.regexLineComments = definedLang.regexLineComments
+	}
+	lang.stringLiterals = definedLang.stringLiterals
+	lang.nestedComments = definedLang.nestedComments
+	lang.docstrings = definedLang.docstrings
+	lang.Scale = definedLang.Scale
+	return lang
+}
+
 func (l *Language) WithRegexLineComments(regexLineComments []string) *Language {
 	var regexLineCommentsCompiled []*regexp.Regexp
 	for _, r := range regexLineComments {
@@ -484,6 +533,70 @@ func (l *Language) WithRegexLineComments(regexLineComments []string) *Language {
 	return l
 }
 
+// WithStringLiterals declares the string and character literals of the language.
+// When several literals begin at the same position, the first declared one is used.
+func (l *Language) WithStringLiterals(literals ...StringLiteral) *Language {
+	l.stringLiterals = literals
+	return l
+}
+
+// WithNestedComments declares the multi-line comments, by their begin marker,
+// which can be nested in a comment of the same kind.
+func (l *Language) WithNestedComments(begins ...string) *Language {
+	l.nestedComments = begins
+	return l
+}
+
+// isNestedComment reports whether the multi-line comment beginning with begin can be nested.
+func (l *Language) isNestedComment(begin string) bool {
+	for _, b := range l.nestedComments {
+		if b == begin {
+			return true
+		}
+	}
+	return false
+}
+
+// stringLiteralAt returns the literal beginning at the start of s.
+func (l *Language) stringLiteralAt(s string) *StringLiteral {
+	for i := range l.stringLiterals {
+		if strings.HasPrefix(s, l.stringLiterals[i].Begin) {
+			return &l.stringLiterals[i]
+		}
+	}
+	return nil
+}
+
+// multiLineCommentAt reports whether a multi-line comment begins at the start of s.
+func (l *Language) multiLineCommentAt(s string) bool {
+	for _, ml := range l.multiLines {
+		if ml[0] != "" && strings.HasPrefix(s, ml[0]) {
+			return true
+		}
+	}
+	return false
+}
+
+// lineCommentAt reports whether a single line comment begins at the start of s.
+func (l *Language) lineCommentAt(s string) bool {
+	for _, lc := range l.lineComments {
+		if lc != "" && strings.HasPrefix(s, lc) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsStringLiteral reports whether a string literal may begin in the line.
+func (l *Language) containsStringLiteral(line string) bool {
+	for _, literal := range l.stringLiterals {
+		if strings.Contains(line, literal.Begin) {
+			return true
+		}
+	}
+	return false
+}
+
 func lang2exts(lang string) (exts string) {
 	var es []string
 	for ext, l := range Exts {
@@ -511,6 +624,14 @@ func lang2exts(lang string) (exts string) {
 // DefinedLanguages is the type information for mapping language name(key) and NewLanguage.
 type DefinedLanguages struct {
 	Langs map[string]*Language
+
+	// exts, filenames and scripts map the extensions, file names and
+	// interpreters of the loaded definitions to their language.
+	exts      map[string]string
+	filenames map[string]string
+	scripts   map[string]string
+	// noBuiltin disables the built-in detection tables.
+	noBuiltin bool
 }
 
 // GetFormattedString return DefinedLanguages as a human-readable string.
@@ -522,11 +643,42 @@ func (langs *DefinedLanguages) GetFormattedString() string {
 	}
 	sort.Strings(printLangs)
 	for _, lang := range printLangs {
-		buf.WriteString(fmt.Sprintf("%-30v (%s)\n", lang, lang2exts(lang)))
+		buf.WriteString(fmt.Sprintf("%-30v (%s)\n", lang, langs.langExts(lang)))
 	}
 	return buf.String()
 }
 
+var (
+	cStringLiterals = []StringLiteral{
+		{Begin: `"`, End: `"`, Escape: `\`},
+		{Begin: `'`, End: `'`, Escape: `\`},
+	}
+	cppStringLiterals = append([]StringLiteral{
+		{Begin: `R"(`, End: `)"`, MultiLine: true},
+	}, cStringLiterals...)
+	csharpStringLiterals = append([]StringLiteral{
+		{Begin: `@"`, End: `"`, MultiLine: true},
+		{Begin: `"""`, End: `"""`, MultiLine: true},
+	}, cStringLiterals...)
+	goStringLiterals = append([]StringLiteral{
+		{Begin: "`", End: "`", MultiLine: true},
+	}, cStringLiterals...)
+	javaStringLiterals = append([]StringLiteral{
+		{Begin: `"""`, End: `"""`, Escape: `\`, MultiLine: true},
+	}, cStringLiterals...)
+	jsStringLiterals = append([]StringLiteral{
+		{Begin: "`", End: "`", Escape: `\`, MultiLine: true},
+	}, cStringLiterals...)
+	kotlinStringLiterals = append([]StringLiteral{
+		{Begin: `"""`, End: `"""`, MultiLine: true},
+	}, cStringLiterals...)
+	// lifetimes make single quotes ambiguous in Rust, character literals are not declared
+	rustStringLiterals = []StringLiteral{
+		{Begin: `r#"`, End: `"#`, MultiLine: true},
+		{Begin: `"`, End: `"`, Escape: `\`, MultiLine: true},
+	}
+)
+
 // NewDefinedLanguages create DefinedLanguages.
 func NewDefinedLanguages() *DefinedLanguages {
 	return &DefinedLanguages{
@@ -548,14 +700,14 @@ func NewDefinedLanguages() *DefinedLanguages {
 			"BASH":                NewLanguage("BASH", []string{"#"}, [][]string{{"", ""}}),
 			"Bicep":               NewLanguage("Bicep", []string{"//"}, [][]string{{"/*", "*/"}}),
 			"BitBake":             NewLanguage("BitBake", []string{"#"}, [][]string{{"", ""}}),
-			"C":                   NewLanguage("C", []string{"//"}, [][]string{{"/*", "*/"}}),
-			"C Header":            NewLanguage("C Header", []string{"//"}, [][]string{{"/*", "*/"}}),
+			"C":                   NewLanguage("C", []string{"//"}, [][]string{{"/*", "*/"}}).WithStringLiterals(cStringLiterals...),
+			"C Header":            NewLanguage("C Header", []string{"//"}, [][]string{{"/*", "*/"}}).WithStringLiterals(cStringLiterals...),
 			"C Shell":             NewLanguage("C Shell", []string{"#"}, [][]string{{"", ""}}),
 			"Cairo":               NewLanguage("Cairo", []string{"//"}, [][]string{{"", ""}}),
 			"Carbon":              NewLanguage("Carbon", []string{"//"}, [][]string{{"", ""}}),
 			"Cap'n Proto":         NewLanguage("Cap'n Proto", []string{"#"}, [][]string{{"", ""}}),
 			"Carp":                NewLanguage("Carp", []string{";"}, [][]string{{"", ""}}),
-			"C#":                  NewLanguage("C#", []string{"//"}, [][]string{{"/*", "*/"}}),
+			"C#":                  NewLanguage("C#", []string{"//"}, [][]string{{"/*", "*/"}}).WithStringLiterals(csharpStringLiterals...),
 			"Chapel":              NewLanguage("Chapel", []string{"//"}, [][]string{{"/*", "*/"}}),
 			"Circom":              NewLanguage("Circom", []string{"//"}, [][]string{{"/*", "*/"}}),
 			"Clojure":             NewLanguage("Clojure", []string{"#", "#_"}, [][]string{{"", ""}}),
@@ -565,28 +717,28 @@ func NewDefinedLanguages() *DefinedLanguages {
 			"ColdFusion":          NewLanguage("ColdFusion", []string{}, [][]string{{"<!---", "--->"}}),
 			"ColdFusion CFScript": NewLanguage("ColdFusion CFScript", []string{"//"}, [][]string{{"/*", "*/"}}),
 			"CMake":               NewLanguage("CMake", []string{"#"}, [][]string{{"", ""}}),
-			"C++":                 NewLanguage("C++", []string{"//"}, [][]string{{"/*", "*/"}}),
-			"C++ Header":          NewLanguage("C++ Header", []string{"//"}, [][]string{{"/*", "*/"}}),
+			"C++":                 NewLanguage("C++", []string{"//"}, [][]string{{"/*", "*/"}}).WithStringLiterals(cppStringLiterals...),
+			"C++ Header":          NewLanguage("C++ Header", []string{"//"}, [][]string{{"/*", "*/"}}).WithStringLiterals(cppStringLiterals...),
 			"Crystal":             NewLanguage("Crystal", []string{"#"}, [][]string{{"", ""}}),
 			"CSS":                 NewLanguage("CSS", []string{"//"}, [][]string{{"/*", "*/"}}),
-			"Cython":              NewLanguage("Cython", []string{"#"}, [][]string{{"\"\"\"", "\"\"\""}}),
+			"Cython":              NewLanguage("Cython", []string{"#"}, [][]string{{"\"\"\"", "\"\"\""}}).WithDocstrings(),
 			"CUDA":                NewLanguage("CUDA", []string{"//"}, [][]string{{"/*", "*/"}}),
-			"D":                   NewLanguage("D", []string{"//"}, [][]string{{"/*", "*/"}}),
-			"Dart":                NewLanguage("Dart", []string{"//", "///"}, [][]string{{"/*", "*/"}}),
+			"D":                   NewLanguage("D", []string{"//"}, [][]string{{"/*", "*/"}, {"/+", "+/"}}).WithNestedComments("/+"),
+			"Dart":                NewLanguage("Dart", []string{"//", "///"}, [][]string{{"/*", "*/"}}).WithNestedComments("/*"),
 			"Dhall":               NewLanguage("Dhall", []string{"--"}, [][]string{{"{-", "-}"}}),
 			"DTrace":              NewLanguage("DTrace", []string{}, [][]string{{"/*", "*/"}}),
 			"Device Tree":         NewLanguage("Device Tree", []string{"//"}, [][]string{{"/*", "*/"}}),
 			"Dockerfile":          NewLanguage("Dockerfile", []string{"#"}, [][]string{{"", ""}}),
 			"Dune":                NewLanguage("Dune", []string{";"}, [][]string{{"", ""}}),
 			"Eiffel":              NewLanguage("Eiffel", []string{"--"}, [][]string{{"", ""}}),
-			"Elm":                 NewLanguage("Elm", []string{"--"}, [][]string{{"{-", "-}"}}),
+			"Elm":                 NewLanguage("Elm", []string{"--"}, [][]string{{"{-", "-}"}}).WithNestedComments("{-"),
 			"Elixir":              NewLanguage("Elixir", []string{"#"}, [][]string{{"", ""}}),
 			"Erlang":              NewLanguage("Erlang", []string{"%"}, [][]string{{"", ""}}),
 			"Expect":              NewLanguage("Expect", []string{"#"}, [][]string{{"", ""}}),
 			"Fish":                NewLanguage("Fish", []string{"#"}, [][]string{{"", ""}}),
 			"Frege":               NewLanguage("Frege", []string{"--"}, [][]string{{"{-", "-}"}}),
 			"F*":                  NewLanguage("F*", []string{"(*", "//"}, [][]string{{"(*", "*)"}}),
-			"F#":                  NewLanguage("F#", []string{"(*"}, [][]string{{"(*", "*)"}}),
+			"F#":                  NewLanguage("F#", []string{"(*"}, [][]string{{"(*", "*)"}}).WithNestedComments("(*"),
 			"Lean":                NewLanguage("Lean", []string{"--"}, [][]string{{"/-", "-/"}}),
 			"Logtalk":             NewLanguage("Logtalk", []string{"%"}, [][]string{{"", ""}}),
 			"Lua":                 NewLanguage("Lua", []string{"--"}, [][]string{{"--[[", "]]"}}),
@@ -599,10 +751,10 @@ func NewDefinedLanguages() *DefinedLanguages {
 			"Gherkin":             NewLanguage("Gherkin", []string{"#"}, [][]string{{"", ""}}),
 			"Gleam":               NewLanguage("Gleam", []string{"//"}, [][]string{{"", ""}}),
 			"GLSL":                NewLanguage("GLSL", []string{"//"}, [][]string{{"/*", "*/"}}),
-			"Go":                  NewLanguage("Go", []string{"//"}, [][]string{{"/*", "*/"}}),
+			"Go":                  NewLanguage("Go", []string{"//"}, [][]string{{"/*", "*/"}}).WithStringLiterals(goStringLiterals...),
 			"Groovy":              NewLanguage("Groovy", []string{"//"}, [][]string{{"/*", "*/"}}),
 			"Handlebars":          NewLanguage("Handlebars", []string{}, [][]string{{"<!--", "-->"}, {"{{!", "}}"}}),
-			"Haskell":             NewLanguage("Haskell", []string{"--"}, [][]string{{"{-", "-}"}}),
+			"Haskell":             NewLanguage("Haskell", []string{"--"}, [][]string{{"{-", "-}"}}).WithNestedComments("{-"),
 			"Haxe":                NewLanguage("Haxe", []string{"//"}, [][]string{{"/*", "*/"}}),
 			"Hurl":                NewLanguage("Hurl", []string{"#"}, [][]string{{"", ""}}),
 			"Hare":                NewLanguage("Hare", []string{"//"}, [][]string{{"", ""}}),
@@ -615,9 +767,9 @@ func NewDefinedLanguages() *DefinedLanguages {
 			"SKILL":               NewLanguage("SKILL", []string{";"}, [][]string{{"/*", "*/"}}),
 			"JAI":                 NewLanguage("JAI", []string{"//"}, [][]string{{"/*", "*/"}}),
 			"Janet":               NewLanguage("Janet", []string{"#"}, [][]string{{"", ""}}),
-			"Java":                NewLanguage("Java", []string{"//"}, [][]string{{"/*", "*/"}}),
+			"Java":                NewLanguage("Java", []string{"//"}, [][]string{{"/*", "*/"}}).WithStringLiterals(javaStringLiterals...),
 			"JSP":                 NewLanguage("JSP", []string{"//"}, [][]string{{"/*", "*/"}}),
-			"JavaScript":          NewLanguage("JavaScript", []string{"//"}, [][]string{{"/*", "*/"}}),
+			"JavaScript":          NewLanguage("JavaScript", []string{"//"}, [][]string{{"/*", "*/"}}).WithStringLiterals(jsStringLiterals...),
 			"Julia":               NewLanguage("Julia", []string{"#"}, [][]string{{"#:=", ":=#"}}),
 			"Jupyter Notebook":    NewLanguage("Jupyter Notebook", []string{"#"}, [][]string{{"", ""}}),
 			"Just":                NewLanguage("Just", []string{"#"}, [][]string{{"", ""}}).WithRegexLineComments([]string{`^#[^!].*`}),
@@ -625,7 +777,7 @@ func NewDefinedLanguages() *DefinedLanguages {
 			"JSX":                 NewLanguage("JSX", []string{"//"}, [][]string{{"/*", "*/"}}),
 			"KakouneScript":       NewLanguage("KakouneScript", []string{"#"}, [][]string{{"", ""}}),
 			"Koka":                NewLanguage("Koka", []string{"//"}, [][]string{{"/*", "*/"}}),
-			"Kotlin":              NewLanguage("Kotlin", []string{"//"}, [][]string{{"/*", "*/"}}),
+			"Kotlin":              NewLanguage("Kotlin", []string{"//"}, [][]string{{"/*", "*/"}}).WithStringLiterals(kotlinStringLiterals...).WithNestedComments("/*"),
 			"LD Script":           NewLanguage("LD Script", []string{"//"}, [][]string{{"/*", "*/"}}),
 			"LESS":                NewLanguage("LESS", []string{"//"}, [][]string{{"/*", "*/"}}),
 			"Objective-C":         NewLanguage("Objective-C", []string{"//"}, [][]string{{"/*", "*/"}}),
@@ -635,7 +787,7 @@ func NewDefinedLanguages() *DefinedLanguages {
 			"Nix":                 NewLanguage("Nix", []string{"#"}, [][]string{{"/*", "*/"}}),
 			"NSIS":                NewLanguage("NSIS", []string{"#", ";"}, [][]string{{"/*", "*/"}}),
 			"Nu":                  NewLanguage("Nu", []string{";", "#"}, [][]string{{"", ""}}),
-			"OCaml":               NewLanguage("OCaml", []string{}, [][]string{{"(*", "*)"}}),
+			"OCaml":               NewLanguage("OCaml", []string{}, [][]string{{"(*", "*)"}}).WithNestedComments("(*"),
 			"Objective-C++":       NewLanguage("Objective-C++", []string{"//"}, [][]string{{"/*", "*/"}}),
 			"Makefile":            NewLanguage("Makefile", []string{"#"}, [][]string{{"", ""}}),
 			"MATLAB":              NewLanguage("MATLAB", []string{"%"}, [][]string{{"%{", "}%"}}),
@@ -646,7 +798,7 @@ func NewDefinedLanguages() *DefinedLanguages {
 			"Move":                NewLanguage("Move", []string{"//"}, [][]string{{"", ""}}),
 			"Mustache":            NewLanguage("Mustache", []string{}, [][]string{{"{{!", "}}"}}),
 			"M4":                  NewLanguage("M4", []string{"#"}, [][]string{{"", ""}}),
-			"Nim":                 NewLanguage("Nim", []string{"#"}, [][]string{{"#[", "]#"}}),
+			"Nim":                 NewLanguage("Nim", []string{"#"}, [][]string{{"#[", "]#"}}).WithNestedComments("#["),
 			"Nunjucks":            NewLanguage("Nunjucks", []string{}, [][]string{{"{#", "#}"}, {"<!--", "-->"}}),
 			"lex":                 NewLanguage("lex", []string{}, [][]string{{"/*", "*/"}}),
 			"Odin":                NewLanguage("Odin", []string{"//"}, [][]string{{"/*", "*/"}}),
@@ -661,7 +813,7 @@ func NewDefinedLanguages() *DefinedLanguages {
 			"Polly":               NewLanguage("Polly", []string{"<!--"}, [][]string{{"<!--", "-->"}}),
 			"Protocol Buffers":    NewLanguage("Protocol Buffers", []string{"//"}, [][]string{{"", ""}}),
 			"PRQL":                NewLanguage("PRQL", []string{"#"}, [][]string{{"", ""}}),
-			"Python":              NewLanguage("Python", []string{"#"}, [][]string{{"\"\"\"", "\"\"\""}}),
+			"Python":              NewLanguage("Python", []string{"#"}, [][]string{{"\"\"\"", "\"\"\""}}).WithDocstrings(),
 			"Q":                   NewLanguage("Q", []string{"/ "}, [][]string{{"\\", "/"}, {"/", "\\"}}),
 			"QML":                 NewLanguage("QML", []string{"//"}, [][]string{{"/*", "*/"}}),
 			"R":                   NewLanguage("R", []string{"#"}, [][]string{{"", ""}}),
@@ -676,8 +828,8 @@ func NewDefinedLanguages() *DefinedLanguages {
 			"Ring":                NewLanguage("Ring", []string{"#", "//"}, [][]string{{"/*", "*/"}}),
 			"Ruby":                NewLanguage("Ruby", []string{"#"}, [][]string{{":=begin", ":=end"}}),
 			"Ruby HTML":           NewLanguage("Ruby HTML", []string{"<!--"}, [][]string{{"<!--", "-->"}}),
-			"Rust":                NewLanguage("Rust", []string{"//", "///", "//!"}, [][]string{{"/*", "*/"}}),
-			"Scala":               NewLanguage("Scala", []string{"//"}, [][]string{{"/*", "*/"}}),
+			"Rust":                NewLanguage("Rust", []string{"//", "///", "//!"}, [][]string{{"/*", "*/"}}).WithStringLiterals(rustStringLiterals...).WithNestedComments("/*"),
+			"Scala":               NewLanguage("Scala", []string{"//"}, [][]string{{"/*", "*/"}}).WithNestedComments("/*"),
 			"Sass":                NewLanguage("Sass", []string{"//"}, [][]string{{"/*", "*/"}}),
 			"Scheme":              NewLanguage("Scheme", []string{";"}, [][]string{{"#|", "|#"}}),
 			"sed":                 NewLanguage("sed", []string{"#"}, [][]string{{"", ""}}),
@@ -688,7 +840,7 @@ func NewDefinedLanguages() *DefinedLanguages {
 			"Standard ML":         NewLanguage("Standard ML", []string{}, [][]string{{"(*", "*)"}}),
 			"SQL":                 NewLanguage("SQL", []string{"--"}, [][]string{{"/*", "*/"}}),
 			"Svelte":              NewLanguage("Svelte", []string{"//"}, [][]string{{"/*", "*/"}, {"<!--", "-->"}}),
-			"Swift":               NewLanguage("Swift", []string{"//"}, [][]string{{"/*", "*/"}}),
+			"Swift":               NewLanguage("Swift", []string{"//"}, [][]string{{"/*", "*/"}}).WithNestedComments("/*"),
 			"Templ":               NewLanguage("Templ", []string{"//"}, [][]string{{"/*", "*/"}}),
 			"Terra":               NewLanguage("Terra", []string{"--"}, [][]string{{"--[[", "]]"}}),
 			"TeX":                 NewLanguage("TeX", []string{"%"}, [][]string{{"", ""}}),
@@ -697,7 +849,7 @@ func NewDefinedLanguages() *DefinedLanguages {
 			"TLA":                 NewLanguage("TLA", []string{"\\*"}, [][]string{{"(*", "*)"}}),
 			"Tcl/Tk":              NewLanguage("Tcl/Tk", []string{"#"}, [][]string{{"", ""}}),
 			"TOML":                NewLanguage("TOML", []string{"#"}, [][]string{{"", ""}}),
-			"TypeScript":          NewLanguage("TypeScript", []string{"//"}, [][]string{{"/*", "*/"}}),
+			"TypeScript":          NewLanguage("TypeScript", []string{"//"}, [][]string{{"/*", "*/"}}).WithStringLiterals(jsStringLiterals...),
 			"HCL":                 NewLanguage("HCL", []string{"#", "//"}, [][]string{{"/*", "*/"}}),
 			"Umka":                NewLanguage("Umka", []string{"//"}, [][]string{{"/*", "*/"}}),
 			"Unity-Prefab":        NewLanguage("Unity-Prefab", []string{}, [][]string{{"", ""}}),
@@ -707,7 +859,7 @@ func NewDefinedLanguages() *DefinedLanguages {
 			"VimL":                NewLanguage("VimL", []string{`"`}, [][]string{{"", ""}}),
 			"Visual Basic":        NewLanguage("Visual Basic", []string{"'"}, [][]string{{"", ""}}),
 			"Vue":                 NewLanguage("Vue", []string{"<!--"}, [][]string{{"<!--", "-->"}}),
-			"Vyper":               NewLanguage("Vyper", []string{"#"}, [][]string{{"\"\"\"", "\"\"\""}}),
+			"Vyper":               NewLanguage("Vyper", []string{"#"}, [][]string{{"\"\"\"", "\"\"\""}}).WithDocstrings(),
 			"WiX":                 NewLanguage("WiX", []string{"<!--"}, [][]string{{"<!--", "-->"}}),
 			"XML":                 NewLanguage("XML", []string{"<!--"}, [][]string{{"<!--", "-->"}}),
 			"XML resource":        NewLanguage("XML resource", []string{"<!--"}, [][]string{{"<!--", "-->"}}),
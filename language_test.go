@@ -99,3 +99,26 @@ func TestGetShebangStartDot(t *testing.T) {
 		t.Errorf("invalid logic. lang=[%v] shebang=[%v]", lang, s)
 	}
 }
+
+func TestGetFileTypeByContent(t *testing.T) {
+	opts := NewClocOptions()
+	tests := []struct {
+		path    string
+		content string
+		ext     string
+	}{
+		{"script", "#!/usr/bin/env python\nprint(1)\n", "py"},
+		{"script.sh", "  #!/bin/bash\necho 1\n", "bash"},
+		{"main.go", "package main\n", "go"},
+		{"script", "#!/usr/bin/env python", ""},
+		{"Makefile", "all:\n", "makefile"},
+		{"app.ts", "const a: number = 1;\nexport default a;\n", "TypeScript"},
+	}
+
+	for _, tt := range tests {
+		ext, _ := getFileType(tt.path, []byte(tt.content), opts)
+		if ext != tt.ext {
+			t.Errorf("invalid logic. path=%v ext=[%v]", tt.path, ext)
+		}
+	}
+}
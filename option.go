@@ -13,6 +13,14 @@ type ClocOptions struct {
 	ReNotMatchDir  *regexp.Regexp
 	ReMatchDir     *regexp.Regexp
 	Fullpath       bool
+	// NoIgnore disables .gitignore, .ignore and .gocloc-ignore handling while walking.
+	NoIgnore bool
+	// IgnoreFiles are additional gitignore formatted files applied to every walked path.
+	IgnoreFiles []string
+	// DocstringAsCode counts the docstrings of Python-like languages as code instead of comments.
+	DocstringAsCode bool
+	// Workers is the number of files analyzed in parallel. Zero or less means the number of CPUs.
+	Workers int
 
 	// OnCode is triggered for each line of code.
 	OnCode func(line string)
@@ -0,0 +1,554 @@
+package gocloc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// OutputTypeDefault is cloc's text output format.
+const OutputTypeDefault string = "default"
+
+// OutputTypeClocXML is Cloc's XML output format.
+const OutputTypeClocXML string = "cloc-xml"
+
+// OutputTypeSloccount is Sloccount output format.
+const OutputTypeSloccount string = "sloccount"
+
+// OutputTypeJSON is JSON output format.
+const OutputTypeJSON string = "json"
+
+// OutputTypeClocJSON is cloc's JSON output format.
+const OutputTypeClocJSON string = "cloc-json"
+
+// OutputTypeMarkdown is Markdown output format.
+const OutputTypeMarkdown string = "markdown"
+
+// OutputTypeCSV is cloc's CSV output format.
+const OutputTypeCSV string = "csv"
+
+// OutputTypeTSV is tab separated CSV output format.
+const OutputTypeTSV string = "tsv"
+
+// OutputTypeYAML is cloc's YAML output format.
+const OutputTypeYAML string = "yaml"
+
+// OutputTypeSQL is cloc's SQL output format.
+const OutputTypeSQL string = "sql"
+
+// OutputTypeHTML is a self-contained HTML page.
+const OutputTypeHTML string = "html"
+
+// OutputTypeTemplate is the output of the text/template in RenderOptions.Template.
+const OutputTypeTemplate string = "template"
+
+const (
+	fileHeader             string = "File"
+	languageHeader         string = "Language"
+	directoryHeader        string = "Directory"
+	commonHeader           string = "files          blank        comment           code"
+	defaultOutputSeparator string = "-------------------------------------------------------------------------" +
+		"-------------------------------------------------------------------------" +
+		"-------------------------------------------------------------------------"
+	languageNameLen int = 27
+)
+
+// RenderOptions are the options of Render and RenderDiff.
+type RenderOptions struct {
+	// ByFile reports the files instead of the languages.
+	ByFile bool
+	// ByFileByLang reports the files followed by the languages,
+	// in the default, markdown, json and cloc-xml output types.
+	ByFileByLang bool
+	// ByDir reports the directories instead of the languages.
+	ByDir bool
+	// DirDepth is the number of path elements below the analyzed path in Paths of the
+	// directories the files are grouped by. 0 groups the files by the directory they are in.
+	DirDepth int
+	// SortTag is the column the languages and files are sorted by:
+	// name, files, blank, comment or code (the default).
+	SortTag string
+	// CSVDelimiter is the field delimiter of the csv output type. The default is ','.
+	CSVDelimiter rune
+	// Version is the version of gocloc written by the formats which have one.
+	Version string
+	// Paths are the analyzed paths, used by the sql and template output types and DirDepth.
+	Paths []string
+	// SQLProject is the project name of the sql output type. The default is the paths.
+	SQLProject string
+	// SQLAppend omits the CREATE TABLE statements of the sql output type.
+	SQLAppend bool
+	// Template is the text/template of the template output type.
+	Template string
+}
+
+// NewRenderOptions returns RenderOptions with the default values.
+func NewRenderOptions() *RenderOptions {
+	return &RenderOptions{
+		SortTag:      "code",
+		CSVDelimiter: ',',
+	}
+}
+
+// SortedFiles returns the files of the result sorted by sortTag.
+func (r *Result) SortedFiles(sortTag string) ClocFiles {
+	var sortedFiles ClocFiles
+	for _, file := range r.Files {
+		sortedFiles = append(sortedFiles, *file)
+	}
+	switch sortTag {
+	case "name":
+		sortedFiles.SortByName()
+	case "comment":
+		sortedFiles.SortByComments()
+	case "blank":
+		sortedFiles.SortByBlanks()
+	default:
+		sortedFiles.SortByCode()
+	}
+	return sortedFiles
+}
+
+// SortedDirectories returns the files of the result grouped by directory
+// cut to depth path elements below the analyzed roots, sorted by sortTag.
+func (r *Result) SortedDirectories(depth int, sortTag string, roots ...string) ClocDirectories {
+	var files ClocFiles
+	for _, file := range r.Files {
+		files = append(files, *file)
+	}
+	sortedDirectories := NewClocDirectoriesByDepth(files, depth, roots...)
+	switch sortTag {
+	case "name":
+	case "files":
+		sortedDirectories.SortByFiles()
+	case "comment":
+		sortedDirectories.SortByComments()
+	case "blank":
+		sortedDirectories.SortByBlanks()
+	default:
+		sortedDirectories.SortByCode()
+	}
+	return sortedDirectories
+}
+
+// SortedLanguages returns the languages of the result which have files, sorted by sortTag.
+func (r *Result) SortedLanguages(sortTag string) Languages {
+	var sortedLanguages Languages
+	for _, language := range r.Languages {
+		if language.FilesCount() != 0 {
+			sortedLanguages = append(sortedLanguages, *language)
+		}
+	}
+	switch sortTag {
+	case "name":
+		sortedLanguages.SortByName()
+	case "files":
+		sortedLanguages.SortByFiles()
+	case "comment":
+		sortedLanguages.SortByComments()
+	case "blank":
+		sortedLanguages.SortByBlanks()
+	default:
+		sortedLanguages.SortByCode()
+	}
+	return sortedLanguages
+}
+
+// Render writes the result to w in the output format.
+func Render(w io.Writer, result *Result, format string, opts *RenderOptions) error {
+	if opts == nil {
+		opts = NewRenderOptions()
+	}
+	total := result.Total
+
+	switch format {
+	case OutputTypeSQL:
+		sortedFiles := result.SortedFiles("name")
+		project := opts.SQLProject
+		if project == "" {
+			project = strings.Join(opts.Paths, " ")
+		}
+		sqlResult := NewSQLResultFromCloc(result, sortedFiles, project)
+		sqlResult.Append = opts.SQLAppend
+		return sqlResult.Encode(w)
+	case OutputTypeHTML:
+		htmlResult := NewHTMLResultFromCloc(result, result.SortedLanguages(opts.SortTag), result.SortedFiles(opts.SortTag))
+		htmlResult.Directories = NewClocDirectoriesByDepth(result.SortedFiles(opts.SortTag), opts.DirDepth, opts.Paths...)
+		htmlResult.Version = opts.Version
+		return htmlResult.Encode(w)
+	case OutputTypeTemplate:
+		data := NewTemplateData(result, result.SortedLanguages(opts.SortTag), result.SortedFiles(opts.SortTag))
+		data.Directories = NewClocDirectoriesByDepth(data.Files, opts.DirDepth, opts.Paths...)
+		data.Version = opts.Version
+		data.Paths = opts.Paths
+		return ExecuteTemplate(w, opts.Template, data)
+	case OutputTypeDefault, OutputTypeMarkdown:
+		if opts.ByFileByLang {
+			return renderFilesLanguagesTables(w, result, format, opts)
+		}
+		return renderTable(w, result, format, opts)
+	}
+
+	if opts.ByFileByLang {
+		sortedFiles := result.SortedFiles(opts.SortTag)
+		sortedLanguages := result.SortedLanguages(opts.SortTag)
+		switch format {
+		case OutputTypeClocXML:
+			xmlResult := NewXMLResultFromCloc(total, sortedLanguages, XMLResultWithLangs)
+			xmlResult.XMLFiles = &XMLResultFiles{
+				Files: sortedFiles,
+				Total: XMLTotalFiles{
+					Code:    total.Code,
+					Comment: total.Comments,
+					Blank:   total.Blanks,
+				},
+			}
+			return xmlResult.EncodeTo(w)
+		case OutputTypeJSON:
+			return writeJSON(w, NewJSONFilesLanguagesResultFromCloc(total, sortedFiles, sortedLanguages))
+		}
+		return fmt.Errorf("output type %s does not support the files and languages report", format)
+	}
+
+	if opts.ByDir {
+		sortedDirectories := result.SortedDirectories(opts.DirDepth, opts.SortTag, opts.Paths...)
+		switch format {
+		case OutputTypeClocXML:
+			return NewXMLResultFromDirectories(total, sortedDirectories).EncodeTo(w)
+		case OutputTypeSloccount:
+			// the directories are the projects of sloccount
+			t := &tableWriter{w: w}
+			for _, dir := range sortedDirectories {
+				for _, file := range dir.Files {
+					t.printf("%v\t%v\t%v\t%v\n", file.Code, file.Lang, dir.Name, file.Name)
+				}
+			}
+			return t.err
+		case OutputTypeJSON:
+			return writeJSON(w, NewJSONDirectoriesResultFromCloc(total, sortedDirectories))
+		case OutputTypeClocJSON:
+			clocResult := NewClocJSONDirectoriesResult(total, sortedDirectories, result.Elapsed)
+			clocResult.Header.Version = opts.Version
+			return writeJSON(w, clocResult)
+		case OutputTypeCSV, OutputTypeTSV:
+			return NewCSVDirectoriesResultFromCloc(total, sortedDirectories).Encode(w, csvDelimiter(format, opts))
+		case OutputTypeYAML:
+			yamlResult := NewYAMLDirectoriesResultFromCloc(total, sortedDirectories, result.Elapsed)
+			yamlResult.Header.Version = opts.Version
+			return yamlResult.Encode(w)
+		}
+		return fmt.Errorf("unknown output type: %s", format)
+	}
+
+	if opts.ByFile {
+		sortedFiles := result.SortedFiles(opts.SortTag)
+		switch format {
+		case OutputTypeClocXML:
+			xmlResult := XMLResult{
+				XMLFiles: &XMLResultFiles{
+					Files: sortedFiles,
+					Total: XMLTotalFiles{
+						Code:    total.Code,
+						Comment: total.Comments,
+						Blank:   total.Blanks,
+					},
+				},
+			}
+			return xmlResult.EncodeTo(w)
+		case OutputTypeSloccount:
+			return renderSloccount(w, sortedFiles)
+		case OutputTypeJSON:
+			return writeJSON(w, NewJSONFilesResultFromCloc(total, sortedFiles))
+		case OutputTypeClocJSON:
+			clocResult := NewClocJSONFilesResult(total, sortedFiles, result.Elapsed)
+			clocResult.Header.Version = opts.Version
+			return writeJSON(w, clocResult)
+		case OutputTypeCSV, OutputTypeTSV:
+			return NewCSVFilesResultFromCloc(total, sortedFiles).Encode(w, csvDelimiter(format, opts))
+		case OutputTypeYAML:
+			yamlResult := NewYAMLFilesResultFromCloc(total, sortedFiles, result.Elapsed)
+			yamlResult.Header.Version = opts.Version
+			return yamlResult.Encode(w)
+		}
+		return fmt.Errorf("unknown output type: %s", format)
+	}
+
+	sortedLanguages := result.SortedLanguages(opts.SortTag)
+	switch format {
+	case OutputTypeClocXML:
+		return NewXMLResultFromCloc(total, sortedLanguages, XMLResultWithLangs).EncodeTo(w)
+	case OutputTypeSloccount:
+		// sloccount has no format for the languages
+		t := &tableWriter{w: w}
+		for _, language := range sortedLanguages {
+			t.printf("%-27v %6v %14v %14v %14v\n",
+				language.Name, language.FilesCount(), language.Blanks, language.Comments, language.Code)
+		}
+		return t.err
+	case OutputTypeJSON:
+		return writeJSON(w, NewJSONLanguagesResultFromCloc(total, sortedLanguages))
+	case OutputTypeClocJSON:
+		clocResult := NewClocJSONLanguagesResult(total, sortedLanguages, result.Elapsed)
+		clocResult.Header.Version = opts.Version
+		return writeJSON(w, clocResult)
+	case OutputTypeCSV, OutputTypeTSV:
+		return NewCSVLanguagesResultFromCloc(total, sortedLanguages).Encode(w, csvDelimiter(format, opts))
+	case OutputTypeYAML:
+		yamlResult := NewYAMLLanguagesResultFromCloc(total, sortedLanguages, result.Elapsed)
+		yamlResult.Header.Version = opts.Version
+		return yamlResult.Encode(w)
+	}
+	return fmt.Errorf("unknown output type: %s", format)
+}
+
+func csvDelimiter(format string, opts *RenderOptions) rune {
+	if format == OutputTypeTSV {
+		return '\t'
+	}
+	if opts.CSVDelimiter == 0 {
+		return ','
+	}
+	return opts.CSVDelimiter
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(buf)
+	return err
+}
+
+func renderSloccount(w io.Writer, sortedFiles ClocFiles) error {
+	for _, file := range sortedFiles {
+		p := ""
+		if strings.HasPrefix(file.Name, "./") || string(file.Name[0]) == "/" {
+			splitPaths := strings.Split(file.Name, string(os.PathSeparator))
+			if len(splitPaths) >= 3 {
+				p = splitPaths[1]
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%v\t%v\t%v\t%v\n",
+			file.Code, file.Lang, p, file.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tableWriter writes the text and markdown tables, keeping the first write error.
+type tableWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (t *tableWriter) printf(format string, a ...interface{}) {
+	if t.err == nil {
+		_, t.err = fmt.Fprintf(t.w, format, a...)
+	}
+}
+
+// renderFilesLanguagesTables writes the table of the files followed by the table of the languages.
+func renderFilesLanguagesTables(w io.Writer, result *Result, format string, opts *RenderOptions) error {
+	tableOpts := *opts
+	tableOpts.ByDir = false
+	tableOpts.ByFile = true
+	if err := renderTable(w, result, format, &tableOpts); err != nil {
+		return err
+	}
+	if format == OutputTypeMarkdown {
+		// separate the tables
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+	tableOpts.ByFile = false
+	return renderTable(w, result, format, &tableOpts)
+}
+
+func renderTable(w io.Writer, result *Result, format string, opts *RenderOptions) error {
+	t := &tableWriter{w: w}
+	total := result.Total
+	nameLen := languageNameLen
+	header := languageHeader
+	var sortedDirectories ClocDirectories
+	if opts.ByDir {
+		sortedDirectories = result.SortedDirectories(opts.DirDepth, opts.SortTag, opts.Paths...)
+		nameLen = len(directoryHeader)
+		for _, dir := range sortedDirectories {
+			nameLen = max(nameLen, len(dir.Name))
+		}
+		header = directoryHeader
+	} else if opts.ByFile {
+		nameLen = result.MaxPathLength
+		header = fileHeader
+	}
+	headerLen := nameLen + 1
+	rowLen := nameLen + len(commonHeader) + 2
+	// the rows of the files and the directories have the width of their names
+	fixedWidth := !opts.ByFile && !opts.ByDir
+
+	// write header
+	if format == OutputTypeDefault {
+		t.printf("%.[2]*[1]s\n", defaultOutputSeparator, rowLen)
+		t.printf("%-[2]*[1]s %[3]s\n", header, headerLen, commonHeader)
+		t.printf("%.[2]*[1]s\n", defaultOutputSeparator, rowLen)
+	} else {
+		allHeaders := fmt.Sprintf("%s%s%s", header, strings.Repeat(" ", headerLen), commonHeader)
+		headerString := "| " + InsertPipesInTheMiddle(allHeaders)
+		t.printf("%s\n", headerString)
+
+		firstColumnEnd := strings.IndexByte(headerString[1:], '|') + 1
+		var align strings.Builder
+		for i := 0; i < len(headerString); i++ {
+			if headerString[i] == '|' {
+				align.WriteByte('|')
+			} else if i == 1 {
+				// Align the first column to the left
+				align.WriteByte(':')
+			} else if headerString[i+1] == '|' && i > firstColumnEnd {
+				// Align the other columns to the right
+				align.WriteByte(':')
+			} else {
+				align.WriteByte('-')
+			}
+		}
+		t.printf("%s\n", align.String())
+	}
+
+	// write rows
+	switch {
+	case opts.ByDir:
+		for _, dir := range sortedDirectories {
+			if format == OutputTypeDefault {
+				t.printf("%-[1]*[2]v %6[3]v %14[4]v %14[5]v %14[6]v\n",
+					nameLen, dir.Name, dir.FilesCount, dir.Blanks, dir.Comments, dir.Code)
+			} else {
+				t.printf("| %-[1]*[2]s |%8[3]v  |%11[4]v |%13[5]v |%8[6]v |\n",
+					nameLen, dir.Name, dir.FilesCount, dir.Blanks, dir.Comments, dir.Code)
+			}
+		}
+	case opts.ByFile:
+		for _, file := range result.SortedFiles(opts.SortTag) {
+			if format == OutputTypeDefault {
+				t.printf("%-[1]*[2]s %21[3]v %14[4]v %14[5]v\n",
+					nameLen, file.Name, file.Blanks, file.Comments, file.Code)
+			} else {
+				t.printf("| %-[1]*[2]s |%8[3]v  |%11[4]v |%13[5]v |%8[6]v |\n",
+					nameLen, file.Name, 1, file.Blanks, file.Comments, file.Code)
+			}
+		}
+	default:
+		for _, language := range result.SortedLanguages(opts.SortTag) {
+			if format == OutputTypeDefault {
+				t.printf("%-27v %6v %14v %14v %14v\n",
+					language.Name, language.FilesCount(), language.Blanks, language.Comments, language.Code)
+			} else {
+				t.printf("| %-20v |%21v |%11v |%13v |%8v |\n",
+					language.Name, language.FilesCount(), language.Blanks, language.Comments, language.Code)
+			}
+		}
+	}
+
+	// write footer
+	if format == OutputTypeDefault {
+		t.printf("%.[2]*[1]s\n", defaultOutputSeparator, rowLen)
+		t.printf("%-[1]*[2]v %6[3]v %14[4]v %14[5]v %14[6]v\n",
+			nameLen, "TOTAL", total.Total, total.Blanks, total.Comments, total.Code)
+		t.printf("%.[2]*[1]s\n", defaultOutputSeparator, rowLen)
+	} else if fixedWidth {
+		t.printf("| %21v|%22v|%12v|%14v|%8v |\n", "", "", "", "", "")
+		t.printf("| %20v |%21v |%11v |%13v |%8v |\n", "TOTAL", total.Total, total.Blanks, total.Comments, total.Code)
+	} else {
+		t.printf("| %-[1]*[2]v |%10v|%12v|%14v|%8v |\n", nameLen, "", "", "", "", "")
+		t.printf("| %-[1]*[2]v |%9v |%11v |%13v |%8v |\n", nameLen, "TOTAL", total.Total, total.Blanks, total.Comments, total.Code)
+	}
+	return t.err
+}
+
+// RenderDiff writes the diff result to w in the output format:
+// default, cloc-xml or json.
+func RenderDiff(w io.Writer, result *DiffResult, format string, opts *RenderOptions) error {
+	if opts == nil {
+		opts = NewRenderOptions()
+	}
+
+	var sortedLanguages DiffLanguages
+	for _, language := range result.Languages {
+		sortedLanguages = append(sortedLanguages, *language)
+	}
+	sortedLanguages.SortByName()
+
+	var sortedFiles DiffFiles
+	for _, file := range result.Files {
+		sortedFiles = append(sortedFiles, *file)
+	}
+	sortedFiles.SortByName()
+
+	switch format {
+	case OutputTypeClocXML:
+		if opts.ByFile {
+			return NewXMLDiffResultFromFiles(result.Total, sortedFiles).EncodeTo(w)
+		}
+		return NewXMLDiffResultFromLanguages(result.Total, sortedLanguages).EncodeTo(w)
+	case OutputTypeJSON:
+		if opts.ByFile {
+			return writeJSON(w, NewJSONDiffFilesResult(result.Total, sortedFiles))
+		}
+		return writeJSON(w, NewJSONDiffLanguagesResult(result.Total, sortedLanguages))
+	case OutputTypeDefault:
+	default:
+		return fmt.Errorf("unknown output type of diff: %s", format)
+	}
+
+	t := &tableWriter{w: w}
+	headerLen := languageNameLen + 1
+	rowLen := languageNameLen + len(commonHeader) + 2
+	header := languageHeader
+	if opts.ByFile {
+		headerLen = result.MaxPathLength + 1
+		rowLen = result.MaxPathLength + len(commonHeader) + 2
+		header = fileHeader
+	}
+	t.printf("%.[2]*[1]s\n", defaultOutputSeparator, rowLen)
+	t.printf("%-[2]*[1]s %[3]s\n", header, headerLen, commonHeader)
+	t.printf("%.[2]*[1]s\n", defaultOutputSeparator, rowLen)
+
+	if opts.ByFile {
+		for _, file := range sortedFiles {
+			t.printf("%s\n", file.Name)
+			for _, status := range DiffStatuses {
+				lines := file.Lines(status)
+				t.printf(" %-[1]*[2]v %21[3]v %14[4]v %14[5]v\n",
+					result.MaxPathLength-1, status, lines.Blanks, lines.Comments, lines.Code)
+			}
+		}
+	} else {
+		for _, language := range sortedLanguages {
+			t.printf("%s\n", language.Name)
+			for _, status := range DiffStatuses {
+				stats := language.Stats(status)
+				t.printf(" %-26v %6v %14v %14v %14v\n",
+					status, stats.FilesCount, stats.Blanks, stats.Comments, stats.Code)
+			}
+		}
+	}
+
+	t.printf("%.[2]*[1]s\n", defaultOutputSeparator, rowLen)
+	t.printf("TOTAL\n")
+	for _, status := range DiffStatuses {
+		stats := result.Total.Stats(status)
+		if opts.ByFile {
+			t.printf(" %-[1]*[2]v %6[3]v %14[4]v %14[5]v %14[6]v\n",
+				result.MaxPathLength-1, status, stats.FilesCount, stats.Blanks, stats.Comments, stats.Code)
+		} else {
+			t.printf(" %-26v %6v %14v %14v %14v\n",
+				status, stats.FilesCount, stats.Blanks, stats.Comments, stats.Code)
+		}
+	}
+	t.printf("%.[2]*[1]s\n", defaultOutputSeparator, rowLen)
+	return t.err
+}
@@ -0,0 +1,212 @@
+package gocloc
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func newTestRenderResult() *Result {
+	return &Result{
+		Total: &Language{Total: 2, Code: 30, Comments: 6, Blanks: 4},
+		Files: map[string]*ClocFile{
+			"main.go": {Name: "main.go", Lang: "Go", Code: 20, Comments: 5, Blanks: 3},
+			"util.py": {Name: "util.py", Lang: "Python", Code: 10, Comments: 1, Blanks: 1},
+		},
+		Languages: map[string]*Language{
+			"Go":     {Name: "Go", Files: []string{"main.go"}, Code: 20, Comments: 5, Blanks: 3},
+			"Python": {Name: "Python", Files: []string{"util.py"}, Code: 10, Comments: 1, Blanks: 1},
+			"C":      {Name: "C"},
+		},
+		MaxPathLength: 7,
+	}
+}
+
+func TestRender(t *testing.T) {
+	result := newTestRenderResult()
+
+	var buf bytes.Buffer
+	if err := Render(&buf, result, OutputTypeDefault, nil); err != nil {
+		t.Fatalf("Render() error. err=[%v]", err)
+	}
+	expected := `-------------------------------------------------------------------------------
+Language                     files          blank        comment           code
+-------------------------------------------------------------------------------
+Go                               1              3              5             20
+Python                           1              1              1             10
+-------------------------------------------------------------------------------
+TOTAL                            2              4              6             30
+-------------------------------------------------------------------------------
+`
+	if buf.String() != expected {
+		t.Errorf("invalid result. '%s'", buf.String())
+	}
+
+	opts := NewRenderOptions()
+	opts.ByFile = true
+	opts.SortTag = "name"
+	buf.Reset()
+	if err := Render(&buf, result, OutputTypeMarkdown, opts); err != nil {
+		t.Fatalf("Render() error. err=[%v]", err)
+	}
+	lines := strings.Split(buf.String(), "\n")
+	if len(lines) != 7 || !strings.HasPrefix(lines[2], "| main.go |") || !strings.HasPrefix(lines[3], "| util.py |") {
+		t.Errorf("invalid result. '%s'", buf.String())
+	}
+
+	buf.Reset()
+	if err := Render(&buf, result, OutputTypeJSON, opts); err != nil {
+		t.Fatalf("Render() error. err=[%v]", err)
+	}
+	var jsonResult JSONFilesResult
+	if err := json.Unmarshal(buf.Bytes(), &jsonResult); err != nil {
+		t.Fatalf("json.Unmarshal() error. err=[%v]", err)
+	}
+	if len(jsonResult.Files) != 2 || jsonResult.Files[0].Name != "main.go" {
+		t.Errorf("invalid result. '%s'", buf.String())
+	}
+
+	opts.CSVDelimiter = ';'
+	buf.Reset()
+	if err := Render(&buf, result, OutputTypeCSV, opts); err != nil {
+		t.Fatalf("Render() error. err=[%v]", err)
+	}
+	if !strings.Contains(buf.String(), "Go;main.go;3;5;20\n") {
+		t.Errorf("invalid result. '%s'", buf.String())
+	}
+
+	for _, outputType := range []string{OutputTypeClocXML, OutputTypeSloccount, OutputTypeClocJSON, OutputTypeTSV, OutputTypeYAML, OutputTypeSQL, OutputTypeHTML} {
+		buf.Reset()
+		if err := Render(&buf, result, outputType, nil); err != nil || buf.Len() == 0 {
+			t.Errorf("invalid logic. output type=%v err=%v", outputType, err)
+		}
+	}
+
+	if err := Render(&buf, result, "unknown", nil); err == nil {
+		t.Errorf("invalid logic. unknown output type should be an error")
+	}
+}
+
+func TestRenderByDir(t *testing.T) {
+	result := newTestRenderResult()
+	result.Files["lib/sub/util.go"] = &ClocFile{Name: "lib/sub/util.go", Lang: "Go", Code: 7, Comments: 1, Blanks: 2}
+	result.Total = &Language{Total: 3, Code: 37, Comments: 7, Blanks: 6}
+
+	opts := NewRenderOptions()
+	opts.ByDir = true
+	opts.DirDepth = 1
+	var buf bytes.Buffer
+	if err := Render(&buf, result, OutputTypeDefault, opts); err != nil {
+		t.Fatalf("Render() error. err=[%v]", err)
+	}
+	expected := `-------------------------------------------------------------
+Directory  files          blank        comment           code
+-------------------------------------------------------------
+.              2              4              6             30
+lib            1              2              1              7
+-------------------------------------------------------------
+TOTAL          3              6              7             37
+-------------------------------------------------------------
+`
+	if buf.String() != expected {
+		t.Errorf("invalid result. '%s'", buf.String())
+	}
+
+	buf.Reset()
+	if err := Render(&buf, result, OutputTypeJSON, opts); err != nil {
+		t.Fatalf("Render() error. err=[%v]", err)
+	}
+	var jsonResult JSONDirectoriesResult
+	if err := json.Unmarshal(buf.Bytes(), &jsonResult); err != nil {
+		t.Fatalf("json.Unmarshal() error. err=[%v]", err)
+	}
+	if len(jsonResult.Directories) != 2 || jsonResult.Directories[1].Name != "lib" || jsonResult.Directories[1].Code != 7 {
+		t.Errorf("invalid result. '%s'", buf.String())
+	}
+
+	for _, outputType := range []string{OutputTypeMarkdown, OutputTypeClocXML, OutputTypeSloccount, OutputTypeClocJSON, OutputTypeCSV, OutputTypeYAML, OutputTypeHTML} {
+		buf.Reset()
+		if err := Render(&buf, result, outputType, opts); err != nil || !strings.Contains(buf.String(), "lib") {
+			t.Errorf("invalid logic. output type=%v err=%v", outputType, err)
+		}
+	}
+}
+
+func TestRenderByFileByLang(t *testing.T) {
+	result := newTestRenderResult()
+	opts := NewRenderOptions()
+	opts.ByFileByLang = true
+
+	var buf bytes.Buffer
+	if err := Render(&buf, result, OutputTypeDefault, opts); err != nil {
+		t.Fatalf("Render() error. err=[%v]", err)
+	}
+	out := buf.String()
+	fileHeaderAt := strings.Index(out, "\nFile ")
+	languageHeaderAt := strings.Index(out, "\nLanguage ")
+	if fileHeaderAt < 0 || languageHeaderAt < fileHeaderAt || strings.Count(out, "\nTOTAL ") != 2 {
+		t.Errorf("invalid result. '%s'", out)
+	}
+
+	buf.Reset()
+	if err := Render(&buf, result, OutputTypeJSON, opts); err != nil {
+		t.Fatalf("Render() error. err=[%v]", err)
+	}
+	var jsonResult JSONFilesLanguagesResult
+	if err := json.Unmarshal(buf.Bytes(), &jsonResult); err != nil {
+		t.Fatalf("json.Unmarshal() error. err=[%v]", err)
+	}
+	if len(jsonResult.Files) != 2 || len(jsonResult.Languages) != 2 || jsonResult.Total.Code != 30 {
+		t.Errorf("invalid result. '%s'", buf.String())
+	}
+
+	buf.Reset()
+	if err := Render(&buf, result, OutputTypeClocXML, opts); err != nil {
+		t.Fatalf("Render() error. err=[%v]", err)
+	}
+	if !strings.Contains(buf.String(), "<files>") || !strings.Contains(buf.String(), "<languages>") {
+		t.Errorf("invalid result. '%s'", buf.String())
+	}
+
+	buf.Reset()
+	if err := Render(&buf, result, OutputTypeMarkdown, opts); err != nil {
+		t.Fatalf("Render() error. err=[%v]", err)
+	}
+	if !strings.Contains(buf.String(), " |\n\n| Language ") {
+		t.Errorf("invalid result. '%s'", buf.String())
+	}
+
+	if err := Render(&buf, result, OutputTypeYAML, opts); err == nil {
+		t.Errorf("invalid logic. yaml should not support the files and languages report")
+	}
+}
+
+func TestRenderDiff(t *testing.T) {
+	oldRoot := writeTestTree(t, map[string]string{"main.go": "package main\n"})
+	newRoot := writeTestTree(t, map[string]string{"main.go": "package main\n\nvar a = 1\n"})
+	result, err := NewProcessor(NewDefinedLanguages(), NewClocOptions()).Diff([]string{oldRoot}, []string{newRoot})
+	if err != nil {
+		t.Fatalf("Diff() error. err=[%v]", err)
+	}
+
+	var buf bytes.Buffer
+	if err := RenderDiff(&buf, result, OutputTypeDefault, nil); err != nil {
+		t.Fatalf("RenderDiff() error. err=[%v]", err)
+	}
+	if !strings.Contains(buf.String(), "\nGo\n same ") || !strings.Contains(buf.String(), "\nTOTAL\n") {
+		t.Errorf("invalid result. '%s'", buf.String())
+	}
+
+	buf.Reset()
+	if err := RenderDiff(&buf, result, OutputTypeClocXML, nil); err != nil {
+		t.Fatalf("RenderDiff() error. err=[%v]", err)
+	}
+	if !strings.HasPrefix(buf.String(), "<?xml") || !strings.Contains(buf.String(), "<diff_results>") {
+		t.Errorf("invalid result. '%s'", buf.String())
+	}
+
+	if err := RenderDiff(&buf, result, OutputTypeYAML, nil); err == nil {
+		t.Errorf("invalid logic. yaml diff should be an error")
+	}
+}
@@ -0,0 +1,454 @@
+package gocloc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ReadResult reads a report written in the output type format back into Result.
+// The json, cloc-json, cloc-xml, yaml, csv, tsv and sql output types can be read.
+// The json output type also reads the reports of cloc-json, and csv detects the
+// delimiter of the report.
+//
+// A report of the files fills Files, Languages and Total. A report of the languages
+// fills Languages and Total only, with the number of files of each language in its Total.
+func ReadResult(r io.Reader, format string) (*Result, error) {
+	switch format {
+	case OutputTypeJSON:
+		return readJSONResult(r)
+	case OutputTypeClocJSON:
+		return readClocResult(r, "cloc-json")
+	case OutputTypeClocXML:
+		return readXMLResult(r)
+	case OutputTypeYAML:
+		return readClocResult(r, "yaml")
+	case OutputTypeCSV, OutputTypeTSV:
+		return readCSVResult(r)
+	case OutputTypeSQL:
+		return readSQLResult(r)
+	}
+	return nil, fmt.Errorf("cannot read the report of output type: %s", format)
+}
+
+func readJSONResult(r io.Reader) (*Result, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(content, &keys); err != nil {
+		return nil, fmt.Errorf("json report: %v", err)
+	}
+	if _, ok := keys["header"]; ok {
+		return readClocResult(bytes.NewReader(content), "cloc-json")
+	}
+
+	var report struct {
+		Languages   []ClocLanguage `json:"languages"`
+		Files       []ClocFile     `json:"files"`
+		Directories []ClocLanguage `json:"directories"`
+	}
+	if err := json.Unmarshal(content, &report); err != nil {
+		return nil, fmt.Errorf("json report: %v", err)
+	}
+	switch {
+	case report.Files != nil:
+		return newResultFromFiles(report.Files), nil
+	case report.Languages != nil:
+		return newResultFromLanguages(report.Languages), nil
+	case report.Directories != nil:
+		return nil, fmt.Errorf("json report: the report of the directories has no languages")
+	}
+	return nil, fmt.Errorf("json report: neither languages nor files")
+}
+
+func readXMLResult(r io.Reader) (*Result, error) {
+	var report XMLResult
+	if err := xml.NewDecoder(r).Decode(&report); err != nil {
+		return nil, fmt.Errorf("xml report: %v", err)
+	}
+	switch {
+	case report.XMLFiles != nil:
+		return newResultFromFiles(report.XMLFiles.Files), nil
+	case report.XMLLanguages != nil:
+		return newResultFromLanguages(report.XMLLanguages.Languages), nil
+	case report.XMLDirectories != nil:
+		return nil, fmt.Errorf("xml report: the report of the directories has no languages")
+	}
+	return nil, fmt.Errorf("xml report: neither languages nor files")
+}
+
+// clocReportEntry is a language, a file or the sum in the JSON and YAML formats of cloc.
+type clocReportEntry struct {
+	NFiles   int32  `yaml:"nFiles"`
+	Blank    int32  `yaml:"blank"`
+	Comment  int32  `yaml:"comment"`
+	Code     int32  `yaml:"code"`
+	Language string `yaml:"language"`
+}
+
+// readClocResult reads the JSON or YAML formats of cloc; YAML is a superset of JSON.
+func readClocResult(r io.Reader, kind string) (*Result, error) {
+	var report map[string]yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&report); err != nil {
+		return nil, fmt.Errorf("%s report: %v", kind, err)
+	}
+
+	var header ClocHeader
+	var langs []ClocLanguage
+	var files []ClocFile
+	for name, node := range report {
+		switch name {
+		case "header":
+			var h struct {
+				ElapsedSeconds float64 `yaml:"elapsed_seconds"`
+			}
+			if err := node.Decode(&h); err != nil {
+				return nil, fmt.Errorf("%s report: %v", kind, err)
+			}
+			header.ElapsedSeconds = h.ElapsedSeconds
+			continue
+		case "SUM":
+			continue
+		}
+
+		var entry clocReportEntry
+		if err := node.Decode(&entry); err != nil {
+			return nil, fmt.Errorf("%s report: %s: %v", kind, name, err)
+		}
+		if entry.Language != "" {
+			files = append(files, ClocFile{
+				Name:     name,
+				Lang:     entry.Language,
+				Code:     entry.Code,
+				Comments: entry.Comment,
+				Blanks:   entry.Blank,
+			})
+		} else {
+			langs = append(langs, ClocLanguage{
+				Name:       name,
+				FilesCount: entry.NFiles,
+				Code:       entry.Code,
+				Comments:   entry.Comment,
+				Blanks:     entry.Blank,
+			})
+		}
+	}
+
+	var result *Result
+	if files != nil {
+		result = newResultFromFiles(files)
+	} else {
+		result = newResultFromLanguages(langs)
+	}
+	result.Elapsed = time.Duration(header.ElapsedSeconds * float64(time.Second))
+	return result, nil
+}
+
+func readCSVResult(r io.Reader) (*Result, error) {
+	reader := bufio.NewReader(r)
+	header, err := reader.Peek(len("language") + 1)
+	if err != nil {
+		return nil, fmt.Errorf("csv report: %v", err)
+	}
+	delimiter := ','
+	for _, field := range []string{"files", "language"} {
+		if strings.HasPrefix(string(header), field) {
+			delimiter, _ = utf8.DecodeRune(header[len(field):])
+		}
+	}
+
+	csvReader := csv.NewReader(reader)
+	csvReader.Comma = delimiter
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("csv report: %v", err)
+	}
+	parseCounts := func(fields []string) ([]int32, error) {
+		counts := make([]int32, len(fields))
+		for i, field := range fields {
+			n, err := strconv.ParseInt(field, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("csv report: %v", err)
+			}
+			counts[i] = int32(n)
+		}
+		return counts, nil
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("csv report: no header")
+	}
+
+	var langs []ClocLanguage
+	var files []ClocFile
+	columns := strings.Join(records[0], ",")
+	for _, record := range records[1:] {
+		if len(record) != 5 {
+			return nil, fmt.Errorf("csv report: %d fields in a row", len(record))
+		}
+		switch columns {
+		case "files,language,blank,comment,code":
+			if record[1] == "SUM" {
+				continue
+			}
+			counts, err := parseCounts([]string{record[0], record[2], record[3], record[4]})
+			if err != nil {
+				return nil, err
+			}
+			langs = append(langs, ClocLanguage{
+				Name:       record[1],
+				FilesCount: counts[0],
+				Blanks:     counts[1],
+				Comments:   counts[2],
+				Code:       counts[3],
+			})
+		case "language,filename,blank,comment,code":
+			if record[0] == "SUM" {
+				continue
+			}
+			counts, err := parseCounts(record[2:])
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, ClocFile{
+				Lang:     record[0],
+				Name:     record[1],
+				Blanks:   counts[0],
+				Comments: counts[1],
+				Code:     counts[2],
+			})
+		default:
+			return nil, fmt.Errorf("csv report: neither languages nor files: %s", columns)
+		}
+	}
+
+	if strings.HasPrefix(columns, "language,") {
+		return newResultFromFiles(files), nil
+	}
+	return newResultFromLanguages(langs), nil
+}
+
+// readSQLResult reads the files and the elapsed time of the insert statements of the sql output type.
+func readSQLResult(r io.Reader) (*Result, error) {
+	result := newResult()
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		var table string
+		for _, t := range []string{"metadata", "t"} {
+			if strings.HasPrefix(line, "insert into "+t+" values(") {
+				table = t
+			}
+		}
+		if table == "" {
+			continue
+		}
+
+		values, err := parseSQLValues(line[strings.IndexByte(line, '(')+1:])
+		if err != nil {
+			return nil, fmt.Errorf("sql report:%d: %v", lineNo, err)
+		}
+		if table == "metadata" {
+			if len(values) != 3 {
+				return nil, fmt.Errorf("sql report:%d: %d values in metadata", lineNo, len(values))
+			}
+			elapsed, err := strconv.ParseFloat(values[2], 64)
+			if err != nil {
+				return nil, fmt.Errorf("sql report:%d: %v", lineNo, err)
+			}
+			result.Elapsed += time.Duration(elapsed * float64(time.Second))
+			continue
+		}
+
+		if len(values) != 9 {
+			return nil, fmt.Errorf("sql report:%d: %d values in t", lineNo, len(values))
+		}
+		var counts [3]int32
+		for i, value := range values[5:8] {
+			n, err := strconv.ParseInt(value, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("sql report:%d: %v", lineNo, err)
+			}
+			counts[i] = int32(n)
+		}
+		result.addFile(ClocFile{
+			Lang:     values[1],
+			Name:     values[2],
+			Blanks:   counts[0],
+			Comments: counts[1],
+			Code:     counts[2],
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// parseSQLValues parses the values of an insert statement up to the closing parenthesis.
+func parseSQLValues(s string) ([]string, error) {
+	var values []string
+	for i := 0; i < len(s); {
+		for i < len(s) && s[i] == ' ' {
+			i++
+		}
+		var value strings.Builder
+		if i < len(s) && s[i] == '\'' {
+			for i++; ; i++ {
+				if i == len(s) {
+					return nil, fmt.Errorf("unterminated string")
+				}
+				if s[i] == '\'' {
+					if i+1 < len(s) && s[i+1] == '\'' {
+						i++
+					} else {
+						i++
+						break
+					}
+				}
+				value.WriteByte(s[i])
+			}
+		} else {
+			for i < len(s) && s[i] != ',' && s[i] != ')' {
+				value.WriteByte(s[i])
+				i++
+			}
+		}
+		values = append(values, strings.TrimSpace(value.String()))
+
+		for i < len(s) && s[i] == ' ' {
+			i++
+		}
+		if i == len(s) {
+			break
+		}
+		switch s[i] {
+		case ',':
+			i++
+		case ')':
+			return values, nil
+		default:
+			return nil, fmt.Errorf("unexpected %q", s[i])
+		}
+	}
+	return nil, fmt.Errorf("missing closing parenthesis")
+}
+
+func newResult() *Result {
+	return &Result{
+		Total:     &Language{},
+		Files:     make(map[string]*ClocFile),
+		Languages: make(map[string]*Language),
+	}
+}
+
+// newResultFromFiles returns Result of the files, with the languages and the total of them.
+func newResultFromFiles(files []ClocFile) *Result {
+	result := newResult()
+	for _, file := range files {
+		result.addFile(file)
+	}
+	return result
+}
+
+// newResultFromLanguages returns Result of the languages, without files.
+func newResultFromLanguages(langs []ClocLanguage) *Result {
+	result := newResult()
+	for _, l := range langs {
+		lang := result.language(l.Name)
+		lang.Code += l.Code
+		lang.Comments += l.Comments
+		lang.Blanks += l.Blanks
+		lang.Total += l.FilesCount
+		result.addTotal(l.FilesCount, l.Code, l.Comments, l.Blanks)
+	}
+	return result
+}
+
+func (r *Result) language(name string) *Language {
+	lang, ok := r.Languages[name]
+	if !ok {
+		lang = &Language{Name: name}
+		r.Languages[name] = lang
+	}
+	return lang
+}
+
+func (r *Result) addTotal(files, code, comments, blanks int32) {
+	r.Total.Total += files
+	r.Total.Code += code
+	r.Total.Comments += comments
+	r.Total.Blanks += blanks
+}
+
+// addFile adds the counts of the file, to those of the file of the same name if any.
+func (r *Result) addFile(file ClocFile) {
+	if f, ok := r.Files[file.Name]; ok {
+		f.Code += file.Code
+		f.Comments += file.Comments
+		f.Blanks += file.Blanks
+	} else {
+		f := file
+		r.Files[file.Name] = &f
+		r.MaxPathLength = max(r.MaxPathLength, len(file.Name))
+	}
+
+	lang := r.language(file.Lang)
+	lang.Files = append(lang.Files, file.Name)
+	lang.Code += file.Code
+	lang.Comments += file.Comments
+	lang.Blanks += file.Blanks
+	lang.Total++
+	r.addTotal(1, file.Code, file.Comments, file.Blanks)
+}
+
+// MergeResults returns the sum of the results by language and by file.
+// The counts of the files of the same name are added up, and the files of
+// each result are counted in the totals.
+func MergeResults(results ...*Result) *Result {
+	merged := newResult()
+	for _, result := range results {
+		var names []string
+		for name := range result.Files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		// the files of the languages without files, as in a language report
+		withFiles := make(map[string]bool)
+		for _, name := range names {
+			file := result.Files[name]
+			merged.addFile(*file)
+			withFiles[file.Lang] = true
+		}
+		for name, l := range result.Languages {
+			if withFiles[name] {
+				continue
+			}
+			lang := merged.language(name)
+			lang.Code += l.Code
+			lang.Comments += l.Comments
+			lang.Blanks += l.Blanks
+			lang.Total += l.FilesCount()
+			merged.addTotal(l.FilesCount(), l.Code, l.Comments, l.Blanks)
+		}
+		merged.Elapsed += result.Elapsed
+	}
+	return merged
+}
@@ -0,0 +1,177 @@
+package gocloc
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMergeResults(t *testing.T) {
+	filesReport := `{"files":[` +
+		`{"code":20,"comment":5,"blank":3,"name":"main.go","language":"Go"},` +
+		`{"code":10,"comment":1,"blank":1,"name":"util.py","language":"Python"}],` +
+		`"total":{"files":2,"code":30,"comment":6,"blank":4}}`
+	languagesReport := `<?xml version="1.0" encoding="UTF-8"?>
+<results>
+  <languages>
+    <language name="Go" files_count="3" code="100" comment="10" blank="20"></language>
+    <language name="C" files_count="1" code="5" comment="0" blank="1"></language>
+    <total sum_files="4" code="105" comment="10" blank="21"></total>
+  </languages>
+</results>
+`
+	yamlReport := `---
+header:
+  elapsed_seconds: 0.5
+main.go:
+  blank: 1
+  comment: 0
+  code: 2
+  language: Go
+SUM:
+  blank: 1
+  comment: 0
+  code: 2
+  nFiles: 1
+`
+
+	var results []*Result
+	for _, report := range []struct {
+		content string
+		format  string
+	}{
+		{filesReport, OutputTypeJSON},
+		{languagesReport, OutputTypeClocXML},
+		{yamlReport, OutputTypeYAML},
+	} {
+		result, err := ReadResult(strings.NewReader(report.content), report.format)
+		if err != nil {
+			t.Fatalf("ReadResult() error. format=%v err=[%v]", report.format, err)
+		}
+		results = append(results, result)
+	}
+	if results[1].Files == nil || len(results[1].Files) != 0 || results[1].Languages["Go"].FilesCount() != 3 {
+		t.Errorf("invalid logic. languages report=%+v", results[1])
+	}
+
+	merged := MergeResults(results...)
+	total := merged.Total
+	if total.Total != 7 || total.Code != 137 || total.Comments != 16 || total.Blanks != 26 {
+		t.Errorf("invalid logic. total=%+v", total)
+	}
+	goLang := merged.Languages["Go"]
+	if goLang.FilesCount() != 5 || goLang.Code != 122 || goLang.Comments != 15 || goLang.Blanks != 24 {
+		t.Errorf("invalid logic. go=%+v", goLang)
+	}
+	if c := merged.Languages["C"]; c == nil || c.FilesCount() != 1 || c.Code != 5 {
+		t.Errorf("invalid logic. c=%+v", c)
+	}
+	if f := merged.Files["main.go"]; f == nil || f.Code != 22 || f.Blanks != 4 {
+		t.Errorf("invalid logic. main.go=%+v", f)
+	}
+	if len(merged.Files) != 2 || merged.MaxPathLength != 7 || merged.Elapsed.Seconds() != 0.5 {
+		t.Errorf("invalid logic. files=%v maxPathLength=%v elapsed=%v", len(merged.Files), merged.MaxPathLength, merged.Elapsed)
+	}
+
+	var buf bytes.Buffer
+	if err := Render(&buf, merged, OutputTypeDefault, nil); err != nil {
+		t.Fatalf("Render() error. err=[%v]", err)
+	}
+	if !strings.Contains(buf.String(), "\nGo                               5 ") {
+		t.Errorf("invalid result. '%s'", buf.String())
+	}
+}
+
+func TestReadResultErrors(t *testing.T) {
+	tests := []struct {
+		content string
+		format  string
+	}{
+		{`{"total":{}}`, OutputTypeJSON},
+		{`{"directories":[{"name":"src"}]}`, OutputTypeJSON},
+		{`not json`, OutputTypeJSON},
+		{`<results></results>`, OutputTypeClocXML},
+		{`- a list`, OutputTypeYAML},
+		{"files,directory,blank,comment,code\n1,src,0,0,1\n", OutputTypeCSV},
+		{strings.Repeat("\n", 11), OutputTypeCSV},
+		{"language,filename,blank,comment,code\nGo,main.go,x,0,1\n", OutputTypeCSV},
+		{"insert into t values('p', 'Go', 'main.go', '.', 'main.go', 0, 0);", OutputTypeSQL},
+		{"insert into t values('p', 'Go', 'main.go", OutputTypeSQL},
+		{`files,language`, "unknown"},
+	}
+	for _, tt := range tests {
+		if _, err := ReadResult(strings.NewReader(tt.content), tt.format); err == nil {
+			t.Errorf("invalid logic. content=%q should be an error", tt.content)
+		}
+	}
+}
+
+func TestReadResultRoundTrip(t *testing.T) {
+	result := newTestRenderResult()
+	result.Files["it's, odd.go"] = &ClocFile{Name: "it's, odd.go", Lang: "Go", Code: 2, Comments: 0, Blanks: 1}
+	result.Languages["Go"].Files = append(result.Languages["Go"].Files, "it's, odd.go")
+	result.Languages["Go"].Code += 2
+	result.Languages["Go"].Blanks++
+	result.Total = &Language{Total: 3, Code: 32, Comments: 6, Blanks: 5}
+	result.Elapsed = 1500 * time.Millisecond
+
+	for _, format := range []string{
+		OutputTypeJSON, OutputTypeClocJSON, OutputTypeClocXML, OutputTypeYAML,
+		OutputTypeCSV, OutputTypeTSV, OutputTypeSQL,
+	} {
+		for _, byFile := range []bool{false, true} {
+			if format == OutputTypeSQL && !byFile {
+				// the sql output type always has the files
+				continue
+			}
+			opts := NewRenderOptions()
+			opts.ByFile = byFile
+			opts.CSVDelimiter = ';'
+			var buf bytes.Buffer
+			if err := Render(&buf, result, format, opts); err != nil {
+				t.Fatalf("Render() error. format=%v err=[%v]", format, err)
+			}
+			read, err := ReadResult(&buf, format)
+			if err != nil {
+				t.Fatalf("ReadResult() error. format=%v byFile=%v err=[%v]", format, byFile, err)
+			}
+
+			total := read.Total
+			if total.Total != 3 || total.Code != 32 || total.Comments != 6 || total.Blanks != 5 {
+				t.Errorf("invalid logic. format=%v byFile=%v total=%+v", format, byFile, total)
+			}
+			if len(read.Languages) != 2 {
+				t.Errorf("invalid logic. format=%v byFile=%v languages=%v", format, byFile, len(read.Languages))
+			}
+			for _, name := range []string{"Go", "Python"} {
+				want, got := result.Languages[name], read.Languages[name]
+				if got == nil || got.FilesCount() != want.FilesCount() || got.Code != want.Code ||
+					got.Comments != want.Comments || got.Blanks != want.Blanks {
+					t.Errorf("invalid logic. format=%v byFile=%v %s=%+v", format, byFile, name, got)
+				}
+			}
+
+			if !byFile {
+				if len(read.Files) != 0 {
+					t.Errorf("invalid logic. format=%v files=%v", format, len(read.Files))
+				}
+				continue
+			}
+			if len(read.Files) != len(result.Files) || read.MaxPathLength != 12 {
+				t.Errorf("invalid logic. format=%v files=%v maxPathLength=%v", format, len(read.Files), read.MaxPathLength)
+			}
+			for name, want := range result.Files {
+				if got := read.Files[name]; got == nil || *got != *want {
+					t.Errorf("invalid logic. format=%v %s=%+v", format, name, got)
+				}
+			}
+			switch format {
+			case OutputTypeClocJSON, OutputTypeYAML, OutputTypeSQL:
+				if read.Elapsed != result.Elapsed {
+					t.Errorf("invalid logic. format=%v elapsed=%v", format, read.Elapsed)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package gocloc
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SQLTimestampFormat is the format of the timestamp of the metadata table.
+const SQLTimestampFormat = "2006-01-02 15:04:05"
+
+// SQLResult defines the result of the analysis as SQL statements,
+// with the metadata and t tables of cloc's --sql option.
+type SQLResult struct {
+	Project   string
+	Timestamp time.Time
+	Elapsed   time.Duration
+	Files     ClocFiles
+	// Scales holds the 3rd generation scale factor by language name.
+	// A language without a scale factor, as the built-in ones, has a factor of 1.
+	Scales map[string]float64
+	// Append omits the CREATE TABLE statements, to add the rows to an existing database.
+	Append bool
+}
+
+// NewSQLResultFromCloc returns SQLResult of the files of the result, tagged with the project name.
+func NewSQLResultFromCloc(result *Result, sortedFiles ClocFiles, project string) *SQLResult {
+	scales := make(map[string]float64, len(result.Languages))
+	for name, language := range result.Languages {
+		scales[name] = language.Scale
+	}
+	return &SQLResult{
+		Project:   project,
+		Timestamp: time.Now(),
+		Elapsed:   result.Elapsed,
+		Files:     sortedFiles,
+		Scales:    scales,
+	}
+}
+
+const sqlCreateTables = `create table metadata (
+                timestamp varchar(80),
+                Project   varchar(80),
+                elapsed_s real);
+create table t (
+                Project       varchar(80),
+                Language      varchar(80),
+                File          varchar(1000),
+                File_dirname  varchar(1000),
+                File_basename varchar(1000),
+                nBlank        integer,
+                nComment      integer,
+                nCode         integer,
+                nScaled       real);
+`
+
+// sqlQuote returns s as a SQL string literal.
+func sqlQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// Encode writes the SQL statements, in a single transaction.
+func (s *SQLResult) Encode(w io.Writer) error {
+	var b strings.Builder
+	if !s.Append {
+		b.WriteString(sqlCreateTables)
+	}
+	b.WriteString("begin transaction;\n")
+	fmt.Fprintf(&b, "insert into metadata values(%s, %s, %g);\n",
+		sqlQuote(s.Timestamp.Format(SQLTimestampFormat)), sqlQuote(s.Project), s.Elapsed.Seconds())
+	for _, file := range s.Files {
+		scale := s.Scales[file.Lang]
+		if scale == 0 {
+			scale = 1
+		}
+		scaled := float64(file.Code) * scale
+		fmt.Fprintf(&b, "insert into t values(%s, %s, %s, %s, %s, %d, %d, %d, %g);\n",
+			sqlQuote(s.Project), sqlQuote(file.Lang), sqlQuote(file.Name),
+			sqlQuote(filepath.Dir(file.Name)), sqlQuote(filepath.Base(file.Name)),
+			file.Blanks, file.Comments, file.Code, scaled)
+	}
+	b.WriteString("commit;\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
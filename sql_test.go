@@ -0,0 +1,73 @@
+package gocloc
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSQLResult(t *testing.T) {
+	result := &Result{
+		Languages: map[string]*Language{
+			"Go": {Name: "Go", Scale: 2.5},
+			"C":  {Name: "C"},
+		},
+		Elapsed: 1500 * time.Millisecond,
+	}
+	files := ClocFiles{
+		{Name: "src/main.go", Lang: "Go", Code: 10, Comments: 2, Blanks: 1},
+		{Name: "src/it's.c", Lang: "C", Code: 4},
+	}
+
+	sqlResult := NewSQLResultFromCloc(result, files, "my project")
+	sqlResult.Timestamp = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	var buf bytes.Buffer
+	if err := sqlResult.Encode(&buf); err != nil {
+		t.Fatalf("Encode() error. err=[%v]", err)
+	}
+	out := buf.String()
+
+	for _, expected := range []string{
+		"create table metadata (",
+		"create table t (",
+		"begin transaction;\n",
+		"insert into metadata values('2024-01-02 03:04:05', 'my project', 1.5);\n",
+		"insert into t values('my project', 'Go', 'src/main.go', 'src', 'main.go', 1, 2, 10, 25);\n",
+		"insert into t values('my project', 'C', 'src/it''s.c', 'src', 'it''s.c', 0, 0, 4, 4);\n",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("invalid logic. expected=%q sql=%v", expected, out)
+		}
+	}
+	if !strings.HasSuffix(out, "commit;\n") {
+		t.Errorf("invalid logic. sql=%v", out)
+	}
+
+	sqlResult.Append = true
+	buf.Reset()
+	if err := sqlResult.Encode(&buf); err != nil {
+		t.Fatalf("Encode() error. err=[%v]", err)
+	}
+	if strings.Contains(buf.String(), "create table") {
+		t.Errorf("invalid logic. append sql=%v", buf.String())
+	}
+}
+
+func TestSQLResultBuiltinScale(t *testing.T) {
+	root := writeTestTree(t, map[string]string{
+		"main.go": "package main\n\n// comment\nfunc main() {}\n",
+	})
+	result, err := NewProcessor(NewDefinedLanguages(), NewClocOptions()).Analyze([]string{root})
+	if err != nil {
+		t.Fatalf("Analyze() error. err=[%v]", err)
+	}
+
+	var buf bytes.Buffer
+	if err := Render(&buf, result, OutputTypeSQL, nil); err != nil {
+		t.Fatalf("Render() error. err=[%v]", err)
+	}
+	if !strings.Contains(buf.String(), "'main.go', 1, 1, 2, 2);\n") {
+		t.Errorf("invalid logic. sql=%v", buf.String())
+	}
+}
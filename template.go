@@ -0,0 +1,174 @@
+package gocloc
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+)
+
+// TemplateData is the data model of the user-defined templates executed by ExecuteTemplate.
+type TemplateData struct {
+	// Languages are the totals of each language, in the order given to NewTemplateData.
+	Languages []ClocLanguage
+	// Files are the counts of each file, in the order given to NewTemplateData.
+	Files ClocFiles
+	// Directories are the totals of the files directly in each directory, sorted by name.
+	Directories ClocDirectories
+	// Total is the total of all files. Its name is "SUM".
+	Total ClocLanguage
+
+	// Version is the version of gocloc, if set by the caller.
+	Version string
+	// Paths are the analyzed paths, if set by the caller.
+	Paths []string
+	// Elapsed is the time spent on the analysis.
+	Elapsed time.Duration
+	// FilesPerSecond and LinesPerSecond are the throughput of the analysis.
+	FilesPerSecond float64
+	LinesPerSecond float64
+}
+
+// NewTemplateData returns TemplateData of the result.
+func NewTemplateData(result *Result, sortedLanguages Languages, sortedFiles ClocFiles) *TemplateData {
+	data := &TemplateData{
+		Files:       sortedFiles,
+		Directories: NewClocDirectories(sortedFiles),
+		Total: ClocLanguage{
+			Name:       "SUM",
+			FilesCount: result.Total.Total,
+			Code:       result.Total.Code,
+			Comments:   result.Total.Comments,
+			Blanks:     result.Total.Blanks,
+		},
+		Elapsed:        result.Elapsed,
+		FilesPerSecond: result.FilesPerSecond(),
+		LinesPerSecond: result.LinesPerSecond(),
+	}
+	for _, language := range sortedLanguages {
+		data.Languages = append(data.Languages, ClocLanguage{
+			Name:       language.Name,
+			FilesCount: language.FilesCount(),
+			Code:       language.Code,
+			Comments:   language.Comments,
+			Blanks:     language.Blanks,
+		})
+	}
+	return data
+}
+
+// TemplateFuncs are the functions available in the templates executed by ExecuteTemplate.
+//
+//	padLeft WIDTH VALUE   VALUE right-aligned in WIDTH characters
+//	padRight WIDTH VALUE  VALUE left-aligned in WIDTH characters
+//	repeat COUNT STRING   STRING repeated COUNT times
+//	percent PART TOTAL    PART in percent of TOTAL, 0 if TOTAL is 0
+//	add COUNT...          the sum of the counts, such as add .Comments .Code
+//	sortBy KEY LIST       a sorted copy of Languages, Files or Directories,
+//	                      KEY is name, files, blank, comment or code
+var TemplateFuncs = template.FuncMap{
+	"padLeft":  padLeft,
+	"padRight": padRight,
+	"repeat":   repeat,
+	"percent":  percent,
+	"add":      add,
+	"sortBy":   sortBy,
+}
+
+// ExecuteTemplate executes the text/template text against data and writes the output to w.
+func ExecuteTemplate(w io.Writer, text string, data *TemplateData) error {
+	tmpl, err := template.New("gocloc").Funcs(TemplateFuncs).Parse(text)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, data)
+}
+
+func padLeft(width int, value interface{}) string {
+	s := fmt.Sprint(value)
+	if n := width - utf8.RuneCountInString(s); n > 0 {
+		return strings.Repeat(" ", n) + s
+	}
+	return s
+}
+
+func padRight(width int, value interface{}) string {
+	s := fmt.Sprint(value)
+	if n := width - utf8.RuneCountInString(s); n > 0 {
+		return s + strings.Repeat(" ", n)
+	}
+	return s
+}
+
+func repeat(count int, s string) string {
+	return strings.Repeat(s, count)
+}
+
+func percent(part, total int32) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) * 100 / float64(total)
+}
+
+func add(counts ...int32) int32 {
+	var sum int32
+	for _, n := range counts {
+		sum += n
+	}
+	return sum
+}
+
+// sortBy sorts by name in ascending order, and by the counts in descending order.
+func sortBy(key string, list interface{}) (interface{}, error) {
+	type counts struct {
+		name                        string
+		files, blank, comment, code int32
+	}
+	var less func(a, b counts) bool
+	switch key {
+	case "name":
+		less = func(a, b counts) bool { return a.name < b.name }
+	case "files":
+		less = func(a, b counts) bool { return a.files > b.files }
+	case "blank":
+		less = func(a, b counts) bool { return a.blank > b.blank }
+	case "comment":
+		less = func(a, b counts) bool { return a.comment > b.comment }
+	case "code":
+		less = func(a, b counts) bool { return a.code > b.code }
+	default:
+		return nil, fmt.Errorf("sortBy: unknown key %q", key)
+	}
+
+	switch l := list.(type) {
+	case []ClocLanguage:
+		sorted := append([]ClocLanguage(nil), l...)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			a, b := sorted[i], sorted[j]
+			return less(counts{a.Name, a.FilesCount, a.Blanks, a.Comments, a.Code},
+				counts{b.Name, b.FilesCount, b.Blanks, b.Comments, b.Code})
+		})
+		return sorted, nil
+	case ClocFiles:
+		sorted := append(ClocFiles(nil), l...)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			a, b := sorted[i], sorted[j]
+			return less(counts{a.Name, 1, a.Blanks, a.Comments, a.Code},
+				counts{b.Name, 1, b.Blanks, b.Comments, b.Code})
+		})
+		return sorted, nil
+	case ClocDirectories:
+		sorted := append(ClocDirectories(nil), l...)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			a, b := sorted[i], sorted[j]
+			return less(counts{a.Name, a.FilesCount, a.Blanks, a.Comments, a.Code},
+				counts{b.Name, b.FilesCount, b.Blanks, b.Comments, b.Code})
+		})
+		return sorted, nil
+	}
+	return nil, fmt.Errorf("sortBy: cannot sort %T", list)
+}
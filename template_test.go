@@ -0,0 +1,53 @@
+package gocloc
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestExecuteTemplate(t *testing.T) {
+	result := &Result{
+		Total:   &Language{Total: 3, Code: 30, Comments: 10, Blanks: 5},
+		Elapsed: time.Second,
+	}
+	languages := Languages{
+		{Name: "Go", Files: []string{"a.go", "src/b.go"}, Code: 20, Comments: 10, Blanks: 3},
+		{Name: "C", Files: []string{"src/c.c"}, Code: 10, Blanks: 2},
+	}
+	files := ClocFiles{
+		{Name: "a.go", Lang: "Go", Code: 15, Comments: 5, Blanks: 2},
+		{Name: "src/b.go", Lang: "Go", Code: 5, Comments: 5, Blanks: 1},
+		{Name: "src/c.c", Lang: "C", Code: 10, Blanks: 2},
+	}
+	data := NewTemplateData(result, languages, files)
+	data.Version = "v1.0.0"
+
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{`{{range .Languages}}{{padRight 4 .Name}}|{{padLeft 4 .Code}}|{{.FilesCount}}
+{{end}}`, "Go  |  20|2\nC   |  10|1\n"},
+		{`{{range sortBy "name" .Languages}}{{.Name}} {{end}}`, "C Go "},
+		{`{{range sortBy "comment" .Files}}{{.Name}} {{end}}`, "a.go src/b.go src/c.c "},
+		{`{{range .Directories}}{{.Name}}:{{.FilesCount}}:{{.Code}} {{end}}`, ".:1:15 src:2:15 "},
+		{`{{printf "%.1f" (percent .Total.Comments (add .Total.Comments .Total.Code))}}`, "25.0"},
+		{`{{repeat 3 "-"}}{{.Total.Name}} {{.Version}} {{.FilesPerSecond}}`, "---SUM v1.0.0 3"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := ExecuteTemplate(&buf, tt.text, data); err != nil {
+			t.Fatalf("ExecuteTemplate() error. err=[%v]", err)
+		}
+		if buf.String() != tt.expected {
+			t.Errorf("invalid logic. template=%q output=%q", tt.text, buf.String())
+		}
+	}
+
+	for _, text := range []string{`{{range`, `{{sortBy "size" .Files}}`, `{{sortBy "name" .Total}}`} {
+		if err := ExecuteTemplate(&bytes.Buffer{}, text, data); err == nil {
+			t.Errorf("invalid logic. template=%q should be an error", text)
+		}
+	}
+}
@@ -1,8 +1,10 @@
 package gocloc
 
 import (
+	"bufio"
 	"crypto/md5"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -56,20 +58,17 @@ func nextRune(s string) rune {
 	return 0
 }
 
-func checkMD5Sum(path string, fileCache map[string]struct{}) (ignore bool) {
-	content, err := os.ReadFile(path)
-	if err != nil {
-		return true
-	}
-
-	// calc md5sum
+func md5Sum(content []byte) string {
 	hash := md5.Sum(content)
-	c := fmt.Sprintf("%x", hash)
-	if _, ok := fileCache[c]; ok {
+	return fmt.Sprintf("%x", hash)
+}
+
+func checkMD5Sum(sum string, fileCache map[string]struct{}) (ignore bool) {
+	if _, ok := fileCache[sum]; ok {
 		return true
 	}
 
-	fileCache[c] = struct{}{}
+	fileCache[sum] = struct{}{}
 	return false
 }
 
@@ -125,18 +124,48 @@ func checkOptionMatch(path string, info os.FileInfo, opts *ClocOptions) bool {
 	return true
 }
 
-// getAllFiles return all the files to be analyzed in paths.
-func getAllFiles(paths []string, languages *DefinedLanguages, opts *ClocOptions) (result map[string]*Language, err error) {
-	result = make(map[string]*Language, 0)
-	fileCache := make(map[string]struct{})
+// checkIgnoreRules reports whether the path matches the ignore rules.
+// Directories are registered with their own ignore files as they are entered.
+func checkIgnoreRules(m *ignoreMatcher, root, path string, info os.FileInfo) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	key := m.key(rel)
+	if rel != "." && m.Ignored(key, info.IsDir()) {
+		return true
+	}
+	if info.IsDir() {
+		m.loadDir(path, key)
+	}
+	return false
+}
 
+// walkFiles walks paths and calls fn in walking order for every file
+// which is not excluded by its path.
+func walkFiles(fsys fileSystem, paths []string, opts *ClocOptions, fn func(root, path string)) (err error) {
 	for _, root := range paths {
 		vcsInRoot := isVCSDir(root)
-		err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		var ignoreRules *ignoreMatcher
+		if !opts.NoIgnore {
+			ignoreRules = newIgnoreMatcher(fsys, root, opts)
+		}
+		err = fsys.Walk(root, func(path string, info os.FileInfo, err error) error {
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "%s\n", err)
 				return nil
 			}
+			if ignoreRules != nil {
+				if ignore := checkIgnoreRules(ignoreRules, root, path, info); ignore {
+					if opts.Debug {
+						fmt.Printf("[ignore=%v] match ignore rules\n", path)
+					}
+					if info.IsDir() {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+			}
 			if ignore := checkDefaultIgnore(path, info, vcsInRoot); ignore {
 				return nil
 			}
@@ -146,45 +175,74 @@ func getAllFiles(paths []string, languages *DefinedLanguages, opts *ClocOptions)
 				return nil
 			}
 
-			if ext, ok := getFileType(path, opts); ok {
-				if targetExt, ok := Exts[ext]; ok {
-					// check exclude extension
-					if _, ok := opts.ExcludeExts[targetExt]; ok {
-						return nil
-					}
-
-					if len(opts.IncludeLangs) != 0 {
-						if _, ok = opts.IncludeLangs[targetExt]; !ok {
-							return nil
-						}
-					}
-
-					if !opts.SkipDuplicated {
-						ignore := checkMD5Sum(path, fileCache)
-						if ignore {
-							if opts.Debug {
-								fmt.Printf("[ignore=%v] find same md5\n", path)
-							}
-							return nil
-						}
-					}
-
-					if _, ok := result[targetExt]; !ok {
-						definedLang := NewLanguage(
-							languages.Langs[targetExt].Name,
-							languages.Langs[targetExt].lineComments,
-							languages.Langs[targetExt].multiLines,
-						)
-						if len(languages.Langs[targetExt].regexLineComments) > 0 {
-							definedLang.regexLineComments = languages.Langs[targetExt].regexLineComments
-						}
-						result[targetExt] = definedLang
-					}
-					result[targetExt].Files = append(result[targetExt].Files, path)
-				}
-			}
+			fn(root, path)
 			return nil
 		})
 	}
 	return
 }
+
+// checkLanguageOption reports whether the language passes the exclude-ext and include-lang options.
+func checkLanguageOption(lang string, opts *ClocOptions) bool {
+	// check exclude extension
+	if _, ok := opts.ExcludeExts[lang]; ok {
+		return false
+	}
+
+	if len(opts.IncludeLangs) != 0 {
+		if _, ok := opts.IncludeLangs[lang]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// sourceHeadSize is the size read ahead to detect the file type by the shebang line.
+const sourceHeadSize = 4096
+
+// readSource opens the file once, detects its language and loads its content.
+// ok is false when the file is not a target of the analysis,
+// in which case only the beginning of the file has been read.
+func readSource(fsys fileSystem, path string, languages *DefinedLanguages, opts *ClocOptions) (lang string, content []byte, ok bool) {
+	fp, err := fsys.Open(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return "", nil, false
+	}
+	defer fp.Close()
+	return readSourceFrom(path, fp, languages, opts)
+}
+
+// readSourceFrom is readSource for the content read from r,
+// whose language is detected by the path.
+func readSourceFrom(path string, r io.Reader, languages *DefinedLanguages, opts *ClocOptions) (lang string, content []byte, ok bool) {
+	var err error
+	reader := bufio.NewReaderSize(r, sourceHeadSize)
+	var head []byte
+	if isDetectedByContent(path) {
+		if content, err = io.ReadAll(reader); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			return "", nil, false
+		}
+		head = content
+	} else {
+		// the peeked bytes stay in the reader
+		head, _ = reader.Peek(sourceHeadSize)
+	}
+
+	lang, ok = languages.detectLanguage(path, head, opts)
+	if !ok || !checkLanguageOption(lang, opts) {
+		return "", nil, false
+	}
+	if _, ok := languages.Langs[lang]; !ok {
+		return "", nil, false
+	}
+
+	if content == nil {
+		if content, err = io.ReadAll(reader); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			return "", nil, false
+		}
+	}
+	return lang, content, true
+}
@@ -23,11 +23,16 @@ func TestContainsComment(t *testing.T) {
 
 func TestCheckMD5SumIgnore(t *testing.T) {
 	fileCache := make(map[string]struct{})
+	content, err := os.ReadFile("./utils_test.go")
+	if err != nil {
+		t.Fatalf("os.ReadFile() error. err=[%v]", err)
+	}
+	sum := md5Sum(content)
 
-	if checkMD5Sum("./utils_test.go", fileCache) {
+	if checkMD5Sum(sum, fileCache) {
 		t.Errorf("invalid sequence")
 	}
-	if !checkMD5Sum("./utils_test.go", fileCache) {
+	if !checkMD5Sum(sum, fileCache) {
 		t.Errorf("invalid sequence")
 	}
 }
@@ -3,6 +3,8 @@ package gocloc
 import (
 	"encoding/xml"
 	"fmt"
+	"io"
+	"os"
 )
 
 // XMLResultType is the result type in XML format.
@@ -42,19 +44,37 @@ type XMLResultFiles struct {
 	Total XMLTotalFiles `xml:"total"`
 }
 
+// XMLResultDirectories stores per directory results in XML format.
+type XMLResultDirectories struct {
+	Directories []ClocLanguage    `xml:"directory"`
+	Total       XMLTotalLanguages `xml:"total"`
+}
+
 // XMLResult stores the results in XML format.
 type XMLResult struct {
-	XMLName      xml.Name            `xml:"results"`
-	XMLFiles     *XMLResultFiles     `xml:"files,omitempty"`
-	XMLLanguages *XMLResultLanguages `xml:"languages,omitempty"`
+	XMLName        xml.Name              `xml:"results"`
+	XMLFiles       *XMLResultFiles       `xml:"files,omitempty"`
+	XMLLanguages   *XMLResultLanguages   `xml:"languages,omitempty"`
+	XMLDirectories *XMLResultDirectories `xml:"directories,omitempty"`
 }
 
 // Encode outputs XMLResult in a human readable format.
 func (x *XMLResult) Encode() {
-	if output, err := xml.MarshalIndent(x, "", "  "); err == nil {
-		fmt.Printf(xml.Header)
-		fmt.Println(string(output))
+	_ = x.EncodeTo(os.Stdout)
+}
+
+// EncodeTo writes XMLResult to w in a human readable format.
+func (x *XMLResult) EncodeTo(w io.Writer) error {
+	return encodeXML(w, x)
+}
+
+func encodeXML(w io.Writer, v interface{}) error {
+	output, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
 	}
+	_, err = fmt.Fprintf(w, "%s%s\n", xml.Header, output)
+	return err
 }
 
 // NewXMLResultFromCloc returns XMLResult with default data set.
@@ -63,7 +83,7 @@ func NewXMLResultFromCloc(total *Language, sortedLanguages Languages, _ XMLResul
 	for _, language := range sortedLanguages {
 		c := ClocLanguage{
 			Name:       language.Name,
-			FilesCount: int32(len(language.Files)),
+			FilesCount: language.FilesCount(),
 			Code:       language.Code,
 			Comments:   language.Comments,
 			Blanks:     language.Blanks,
@@ -85,3 +105,114 @@ func NewXMLResultFromCloc(total *Language, sortedLanguages Languages, _ XMLResul
 		XMLLanguages: f,
 	}
 }
+
+// NewXMLResultFromDirectories returns XMLResult with a directory element for each directory.
+func NewXMLResultFromDirectories(total *Language, sortedDirectories ClocDirectories) *XMLResult {
+	return &XMLResult{
+		XMLDirectories: &XMLResultDirectories{
+			Directories: sortedDirectories.ClocLanguages(),
+			Total: XMLTotalLanguages{
+				Code:     total.Code,
+				Comment:  total.Comments,
+				Blank:    total.Blanks,
+				SumFiles: total.Total,
+			},
+		},
+	}
+}
+
+// XMLDiffResultStatus stores the diff results of one status in XML format.
+type XMLDiffResultStatus struct {
+	Languages []ClocLanguage    `xml:"language,omitempty"`
+	Files     []ClocFile        `xml:"file,omitempty"`
+	Total     XMLTotalLanguages `xml:"total"`
+}
+
+// XMLDiffResult stores the diff results in XML format.
+type XMLDiffResult struct {
+	XMLName  xml.Name             `xml:"diff_results"`
+	Added    *XMLDiffResultStatus `xml:"added"`
+	Removed  *XMLDiffResultStatus `xml:"removed"`
+	Modified *XMLDiffResultStatus `xml:"modified"`
+	Same     *XMLDiffResultStatus `xml:"same"`
+}
+
+// Encode outputs XMLDiffResult in a human readable format.
+func (x *XMLDiffResult) Encode() {
+	_ = x.EncodeTo(os.Stdout)
+}
+
+// EncodeTo writes XMLDiffResult to w in a human readable format.
+func (x *XMLDiffResult) EncodeTo(w io.Writer) error {
+	return encodeXML(w, x)
+}
+
+// Status returns the results of the status.
+func (x *XMLDiffResult) Status(status DiffStatus) *XMLDiffResultStatus {
+	switch status {
+	case DiffModified:
+		return x.Modified
+	case DiffAdded:
+		return x.Added
+	case DiffRemoved:
+		return x.Removed
+	default:
+		return x.Same
+	}
+}
+
+func newXMLDiffResult(total *DiffLanguage) *XMLDiffResult {
+	x := &XMLDiffResult{
+		Added:    &XMLDiffResultStatus{},
+		Removed:  &XMLDiffResultStatus{},
+		Modified: &XMLDiffResultStatus{},
+		Same:     &XMLDiffResultStatus{},
+	}
+	for _, status := range DiffStatuses {
+		stats := total.Stats(status)
+		x.Status(status).Total = XMLTotalLanguages{
+			SumFiles: stats.FilesCount,
+			Code:     stats.Code,
+			Comment:  stats.Comments,
+			Blank:    stats.Blanks,
+		}
+	}
+	return x
+}
+
+// NewXMLDiffResultFromLanguages returns XMLDiffResult of each language.
+func NewXMLDiffResultFromLanguages(total *DiffLanguage, sortedLanguages DiffLanguages) *XMLDiffResult {
+	x := newXMLDiffResult(total)
+	for _, language := range sortedLanguages {
+		for _, status := range DiffStatuses {
+			stats := *language.Stats(status)
+			if stats.FilesCount == 0 && stats.Code == 0 && stats.Comments == 0 && stats.Blanks == 0 {
+				continue
+			}
+			stats.Name = language.Name
+			x.Status(status).Languages = append(x.Status(status).Languages, stats)
+		}
+	}
+	return x
+}
+
+// NewXMLDiffResultFromFiles returns XMLDiffResult of each file.
+func NewXMLDiffResultFromFiles(total *DiffLanguage, sortedFiles DiffFiles) *XMLDiffResult {
+	x := newXMLDiffResult(total)
+	for _, file := range sortedFiles {
+		for _, status := range DiffStatuses {
+			lines := file.Lines(status)
+			if lines.Code == 0 && lines.Comments == 0 && lines.Blanks == 0 {
+				continue
+			}
+			x.Status(status).Files = append(x.Status(status).Files, ClocFile{
+				Code:     lines.Code,
+				Comments: lines.Comments,
+				Blanks:   lines.Blanks,
+				Name:     file.Name,
+				Lang:     file.Lang,
+			})
+		}
+	}
+	return x
+}
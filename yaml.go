@@ -0,0 +1,133 @@
+package gocloc
+
+import (
+	"io"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAMLResult defines the result of the analysis in the YAML format of cloc --yaml.
+// Either Languages or Files is set.
+type YAMLResult struct {
+	Header    ClocHeader
+	Languages []ClocLanguage
+	Files     []ClocFile
+	Total     ClocLanguage
+}
+
+func newYAMLResult(total *Language, elapsed time.Duration) *YAMLResult {
+	return &YAMLResult{
+		Header: NewClocHeader(total, elapsed),
+		Total: ClocLanguage{
+			Name:       "SUM",
+			FilesCount: total.Total,
+			Code:       total.Code,
+			Comments:   total.Comments,
+			Blanks:     total.Blanks,
+		},
+	}
+}
+
+// NewYAMLLanguagesResultFromCloc returns YAMLResult with a map for each language.
+func NewYAMLLanguagesResultFromCloc(total *Language, sortedLanguages Languages, elapsed time.Duration) *YAMLResult {
+	result := newYAMLResult(total, elapsed)
+	for _, language := range sortedLanguages {
+		result.Languages = append(result.Languages, ClocLanguage{
+			Name:       language.Name,
+			FilesCount: language.FilesCount(),
+			Code:       language.Code,
+			Comments:   language.Comments,
+			Blanks:     language.Blanks,
+		})
+	}
+	return result
+}
+
+// NewYAMLDirectoriesResultFromCloc returns YAMLResult with a map for each directory.
+func NewYAMLDirectoriesResultFromCloc(total *Language, sortedDirectories ClocDirectories, elapsed time.Duration) *YAMLResult {
+	result := newYAMLResult(total, elapsed)
+	result.Languages = sortedDirectories.ClocLanguages()
+	return result
+}
+
+// NewYAMLFilesResultFromCloc returns YAMLResult with a map for each file.
+func NewYAMLFilesResultFromCloc(total *Language, sortedFiles ClocFiles, elapsed time.Duration) *YAMLResult {
+	result := newYAMLResult(total, elapsed)
+	result.Files = sortedFiles
+	return result
+}
+
+func yamlString(s string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s}
+}
+
+func yamlInt(n int32) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.FormatInt(int64(n), 10)}
+}
+
+func yamlFloat(f float64) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: strconv.FormatFloat(f, 'f', -1, 64)}
+}
+
+// yamlField is a key and value pair of a mapping.
+type yamlField struct {
+	key   string
+	value *yaml.Node
+}
+
+// yamlMapping returns a mapping node of the fields, in order.
+func yamlMapping(fields []yamlField) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, f := range fields {
+		node.Content = append(node.Content, yamlString(f.key), f.value)
+	}
+	return node
+}
+
+// Encode writes the result in the YAML format.
+func (y *YAMLResult) Encode(w io.Writer) error {
+	h := y.Header
+	doc := yamlMapping([]yamlField{{"header", yamlMapping([]yamlField{
+		{"cloc_url", yamlString(h.URL)},
+		{"cloc_version", yamlString(h.Version)},
+		{"elapsed_seconds", yamlFloat(h.ElapsedSeconds)},
+		{"n_files", yamlInt(h.NFiles)},
+		{"n_lines", yamlInt(h.NLines)},
+		{"files_per_second", yamlFloat(h.FilesPerSecond)},
+		{"lines_per_second", yamlFloat(h.LinesPerSecond)},
+	})}})
+	for _, language := range y.Languages {
+		doc.Content = append(doc.Content, yamlString(language.Name), yamlMapping([]yamlField{
+			{"nFiles", yamlInt(language.FilesCount)},
+			{"blank", yamlInt(language.Blanks)},
+			{"comment", yamlInt(language.Comments)},
+			{"code", yamlInt(language.Code)},
+		}))
+	}
+	for _, file := range y.Files {
+		doc.Content = append(doc.Content, yamlString(file.Name), yamlMapping([]yamlField{
+			{"blank", yamlInt(file.Blanks)},
+			{"comment", yamlInt(file.Comments)},
+			{"code", yamlInt(file.Code)},
+			{"language", yamlString(file.Lang)},
+		}))
+	}
+	doc.Content = append(doc.Content, yamlString("SUM"), yamlMapping([]yamlField{
+		{"blank", yamlInt(y.Total.Blanks)},
+		{"comment", yamlInt(y.Total.Comments)},
+		{"code", yamlInt(y.Total.Code)},
+		{"nFiles", yamlInt(y.Total.FilesCount)},
+	}))
+
+	if _, err := io.WriteString(w, "---\n# "+h.URL+"\n"); err != nil {
+		return err
+	}
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
@@ -0,0 +1,74 @@
+package gocloc
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestYAMLLanguagesResult(t *testing.T) {
+	total := &Language{Total: 3, Code: 30, Comments: 6, Blanks: 4}
+	langs := Languages{
+		{Name: "Go", Files: []string{"a.go", "b.go"}, Code: 20, Comments: 5, Blanks: 3},
+		{Name: "C", Files: []string{"c.c"}, Code: 10, Comments: 1, Blanks: 1},
+	}
+
+	result := NewYAMLLanguagesResultFromCloc(total, langs, 2*time.Second)
+	result.Header.Version = "v1.0.0"
+	var buf bytes.Buffer
+	if err := result.Encode(&buf); err != nil {
+		t.Fatalf("Encode() error. err=[%v]", err)
+	}
+	if !strings.HasPrefix(buf.String(), "---\n# github.com/hhatto/gocloc\nheader:\n") {
+		t.Errorf("invalid logic. yaml=%v", buf.String())
+	}
+
+	var decoded struct {
+		Header map[string]interface{}    `yaml:"header"`
+		Go     map[string]int            `yaml:"Go"`
+		Sum    map[string]int            `yaml:"SUM"`
+		Rest   map[string]map[string]int `yaml:",inline"`
+	}
+	if err := yaml.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("yaml.Unmarshal() error. err=[%v]", err)
+	}
+	if decoded.Header["cloc_version"] != "v1.0.0" || decoded.Header["n_files"] != 3 || decoded.Header["n_lines"] != 40 {
+		t.Errorf("invalid logic. header=%v", decoded.Header)
+	}
+	if decoded.Header["files_per_second"] != 1.5 || decoded.Header["lines_per_second"] != 20.0 {
+		t.Errorf("invalid logic. header=%v", decoded.Header)
+	}
+	if decoded.Go["nFiles"] != 2 || decoded.Go["code"] != 20 || decoded.Go["comment"] != 5 || decoded.Go["blank"] != 3 {
+		t.Errorf("invalid logic. Go=%v", decoded.Go)
+	}
+	if decoded.Rest["C"]["code"] != 10 {
+		t.Errorf("invalid logic. C=%v", decoded.Rest["C"])
+	}
+	if decoded.Sum["nFiles"] != 3 || decoded.Sum["code"] != 30 {
+		t.Errorf("invalid logic. SUM=%v", decoded.Sum)
+	}
+}
+
+func TestYAMLFilesResult(t *testing.T) {
+	total := &Language{Total: 1, Code: 3, Comments: 2, Blanks: 1}
+	files := ClocFiles{{Name: "src/a: b.go", Lang: "Go", Code: 3, Comments: 2, Blanks: 1}}
+
+	var buf bytes.Buffer
+	if err := NewYAMLFilesResultFromCloc(total, files, 0).Encode(&buf); err != nil {
+		t.Fatalf("Encode() error. err=[%v]", err)
+	}
+	var decoded map[string]map[string]interface{}
+	if err := yaml.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("yaml.Unmarshal() error. err=[%v]", err)
+	}
+	file := decoded["src/a: b.go"]
+	if file["language"] != "Go" || file["code"] != 3 || file["comment"] != 2 || file["blank"] != 1 {
+		t.Errorf("invalid logic. file=%v", file)
+	}
+	if decoded["header"]["files_per_second"] != 0.0 {
+		t.Errorf("invalid logic. header=%v", decoded["header"])
+	}
+}